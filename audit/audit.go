@@ -0,0 +1,289 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/hello/auth"
+	"example.com/hello/logging"
+	"example.com/hello/rag"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// saveTimeout bounds writing one audit record after the answer was generated
+const saveTimeout = 5 * time.Second
+
+// Store persists every chat exchange for compliance (누가 무엇을 묻고 어떤 답을 받았는지)
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+// Record is one question and the answer the bot gave
+type Record struct {
+	ID       int64  `json:"id"`
+	AnswerID string `json:"answer_id"`
+	TenantID string `json:"tenant_id"`
+	// PrincipalID는 API key ID 또는 JWT principal ID, User는 JWT subject
+	PrincipalID      string   `json:"principal_id"`
+	PrincipalName    string   `json:"principal_name,omitempty"`
+	User             string   `json:"user,omitempty"`
+	Collection       string   `json:"collection"`
+	Question         string   `json:"question"`
+	Answer           string   `json:"answer"`
+	Sources          []Source `json:"sources"`
+	Model            string   `json:"model"`
+	Prompt           string   `json:"prompt"`
+	LatencyMS        int64    `json:"latency_ms"`
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	// Incomplete이면 답변이 Error로 중단되어 Answer는 사용자에게 전달된 일부
+	Incomplete bool      `json:"incomplete,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Source is a document the answer was based on.
+// Version은 답변 당시 문서 내용의 hash로, 문서가 나중에 바뀌었는지 확인할 수 있다.
+type Source struct {
+	DocumentID int     `json:"document_id"`
+	Collection string  `json:"collection,omitempty"`
+	Version    string  `json:"version"`
+	Score      float64 `json:"score"`
+}
+
+// Query filters audit records. 빈 값은 조건 없음
+type Query struct {
+	Principal  string `form:"principal"`
+	User       string `form:"user"`
+	Collection string `form:"collection"`
+	DocumentID int    `form:"document_id"`
+	// Text는 질문과 답변에서 찾을 문자열 (대소문자 무시)
+	Text  string    `form:"q"`
+	Since time.Time `form:"since"`
+	Until time.Time `form:"until"`
+	// BeforeID는 다음 페이지 cursor (이전 응답의 마지막 ID)
+	BeforeID int64 `form:"before_id"`
+	Limit    int   `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// NewStore creates a new audit store
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+// Migrate creates the chat_audit table
+func (s *Store) Migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS chat_audit (
+            id                BIGSERIAL PRIMARY KEY,
+            answer_id         TEXT NOT NULL,
+            tenant_id         TEXT NOT NULL,
+            principal_id      TEXT NOT NULL,
+            principal_name    TEXT NOT NULL DEFAULT '',
+            "user"            TEXT NOT NULL DEFAULT '',
+            collection        TEXT NOT NULL,
+            question          TEXT NOT NULL,
+            answer            TEXT NOT NULL,
+            sources           JSONB NOT NULL,
+            model             TEXT NOT NULL,
+            prompt            TEXT NOT NULL,
+            latency_ms        BIGINT NOT NULL,
+            prompt_tokens     INTEGER NOT NULL,
+            completion_tokens INTEGER NOT NULL,
+            created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+        )`,
+		`ALTER TABLE chat_audit ADD COLUMN IF NOT EXISTS incomplete BOOLEAN NOT NULL DEFAULT false`,
+		`ALTER TABLE chat_audit ADD COLUMN IF NOT EXISTS error TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS chat_audit_tenant_created_idx ON chat_audit (tenant_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS chat_audit_sources_idx ON chat_audit USING GIN (sources jsonb_path_ops)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// Observe is a rag.Pipeline result observer that records every answer.
+// 감사 로그는 누락되면 안 되므로 background queue 없이 요청 안에서 저장한다.
+func (s *Store) Observe(ctx context.Context, r *rag.Retrieval, res *rag.Result) {
+	rec := NewRecord(r, res)
+	if p, ok := auth.FromContext(ctx); ok {
+		rec.PrincipalID = p.ID
+		rec.PrincipalName = p.Name
+		rec.User = p.User
+	}
+
+	// client가 연결을 끊어도 기록은 남긴다
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+	defer cancel()
+	if err := s.Save(ctx, &rec); err != nil {
+		logging.FromContext(ctx).Error("failed to write audit record", "answer_id", rec.AnswerID, "error", err)
+	}
+}
+
+// NewRecord builds the audit record of a generated answer (principal은 호출자가 채운다)
+func NewRecord(r *rag.Retrieval, res *rag.Result) Record {
+	sources := make([]Source, len(res.Sources))
+	for i, doc := range res.Sources {
+		sources[i] = Source{
+			DocumentID: doc.DocumentID,
+			Collection: doc.Collection,
+			Version:    ContentVersion(doc.Content),
+			Score:      doc.Score,
+		}
+	}
+	return Record{
+		AnswerID:         res.ID,
+		TenantID:         r.Request.Tenant.ID,
+		Collection:       r.Request.Collection,
+		Question:         r.Request.Question,
+		Answer:           res.Answer,
+		Sources:          sources,
+		Model:            res.Model,
+		Prompt:           res.Prompt,
+		LatencyMS:        time.Since(r.StartedAt).Milliseconds(),
+		PromptTokens:     res.Usage.PromptTokens,
+		CompletionTokens: res.Usage.CompletionTokens,
+		Incomplete:       res.Incomplete,
+		Error:            res.Error,
+	}
+}
+
+// ContentVersion returns a short hash identifying the content of a document
+func ContentVersion(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Save writes one record
+func (s *Store) Save(ctx context.Context, rec *Record) error {
+	sources, err := json.Marshal(rec.Sources)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sources: %w", err)
+	}
+	err = s.pool.QueryRow(ctx, `
+        INSERT INTO chat_audit (answer_id, tenant_id, principal_id, principal_name, "user", collection,
+                                question, answer, sources, model, prompt, latency_ms, prompt_tokens, completion_tokens,
+                                incomplete, error)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+        RETURNING id, created_at
+    `, rec.AnswerID, rec.TenantID, rec.PrincipalID, rec.PrincipalName, rec.User, rec.Collection,
+		rec.Question, rec.Answer, sources, rec.Model, rec.Prompt, rec.LatencyMS, rec.PromptTokens, rec.CompletionTokens,
+		rec.Incomplete, rec.Error,
+	).Scan(&rec.ID, &rec.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save audit record: %w", err)
+	}
+	return nil
+}
+
+// Search returns records of tenantID matching q, newest first
+func (s *Store) Search(ctx context.Context, tenantID string, q Query) ([]Record, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	records := []Record{}
+	err := s.Each(ctx, tenantID, q, func(rec Record) error {
+		records = append(records, rec)
+		return nil
+	})
+	return records, err
+}
+
+// Each streams every record of tenantID matching q to fn, newest first (q.Limit이 0이면 제한 없음)
+func (s *Store) Each(ctx context.Context, tenantID string, q Query, fn func(Record) error) error {
+	where, args := q.where(tenantID)
+	sql := `
+        SELECT id, answer_id, tenant_id, principal_id, principal_name, "user", collection,
+               question, answer, sources, model, prompt, latency_ms, prompt_tokens, completion_tokens,
+               incomplete, error, created_at
+        FROM chat_audit
+        WHERE ` + where + `
+        ORDER BY id DESC`
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		sql += " LIMIT $" + strconv.Itoa(len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to search audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec Record
+		var sources []byte
+		if err := rows.Scan(&rec.ID, &rec.AnswerID, &rec.TenantID, &rec.PrincipalID, &rec.PrincipalName, &rec.User,
+			&rec.Collection, &rec.Question, &rec.Answer, &sources, &rec.Model, &rec.Prompt, &rec.LatencyMS,
+			&rec.PromptTokens, &rec.CompletionTokens, &rec.Incomplete, &rec.Error, &rec.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		if err := json.Unmarshal(sources, &rec.Sources); err != nil {
+			return fmt.Errorf("failed to parse audit sources: %w", err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// where builds the WHERE clause of q
+func (q Query) where(tenantID string) (string, []any) {
+	args := []any{tenantID}
+	conds := []string{"tenant_id = $1"}
+	add := func(cond string, value any) {
+		args = append(args, value)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if q.Principal != "" {
+		add("principal_id = ?", q.Principal)
+	}
+	if q.User != "" {
+		add(`"user" = ?`, q.User)
+	}
+	if q.Collection != "" {
+		add("collection = ?", q.Collection)
+	}
+	if q.DocumentID > 0 {
+		add("sources @> ?::jsonb", fmt.Sprintf(`[{"document_id": %d}]`, q.DocumentID))
+	}
+	if q.Text != "" {
+		add("(question ILIKE ? OR answer ILIKE ?)", "%"+escapeLike(q.Text)+"%")
+	}
+	if !q.Since.IsZero() {
+		add("created_at >= ?", q.Since)
+	}
+	if !q.Until.IsZero() {
+		add("created_at < ?", q.Until)
+	}
+	if q.BeforeID > 0 {
+		add("id < ?", q.BeforeID)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Purge deletes records of tenantID created before cutoff
+func (s *Store) Purge(ctx context.Context, tenantID string, cutoff time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+        DELETE FROM chat_audit WHERE tenant_id = $1 AND created_at < $2
+    `, tenantID, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge audit log: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
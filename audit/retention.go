@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"example.com/hello/tenant"
+)
+
+// Purger deletes a tenant's records created before cutoff
+type Purger interface {
+	Purge(ctx context.Context, tenantID string, cutoff time.Time) (int64, error)
+}
+
+// Retention deletes chat records older than each tenant's retention period.
+// 질문과 답변 원문을 가진 모든 저장소(감사 로그, answer trace)에 같은 기준을 적용한다.
+type Retention struct {
+	tenants     *tenant.Store
+	defaultDays int
+	stores      []Purger
+}
+
+// NewRetention creates a retention policy over stores. tenant의 audit_retention_days가 0이면 defaultDays를 사용하고,
+// defaultDays도 0이면 삭제하지 않는다.
+func NewRetention(tenants *tenant.Store, defaultDays int, stores ...Purger) *Retention {
+	return &Retention{tenants: tenants, defaultDays: defaultDays, stores: stores}
+}
+
+// Run applies the policy now and then every interval until ctx is done
+func (r *Retention) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Apply(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to apply audit retention", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Apply deletes expired records of every tenant
+func (r *Retention) Apply(ctx context.Context) error {
+	tenants, err := r.tenants.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		days := t.AuditRetentionDays
+		if days == 0 {
+			days = r.defaultDays
+		}
+		if days <= 0 {
+			continue
+		}
+		cutoff := time.Now().AddDate(0, 0, -days)
+		var deleted int64
+		for _, store := range r.stores {
+			n, err := store.Purge(ctx, t.ID, cutoff)
+			if err != nil {
+				return err
+			}
+			deleted += n
+		}
+		if deleted > 0 {
+			slog.Info("expired audit records deleted", "tenant", t.ID, "deleted", deleted, "retention_days", days)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefetchInterval limits how often an unknown kid or a failed load may trigger a refetch
+const jwksRefetchInterval = 30 * time.Second
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS loads signing keys from a JWKS URL or a local file (테스트용)
+type JWKS struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// 가져오기에 실패하면 retryAt까지 다시 시도하지 않고 lastErr(키가 없을 때) 또는 이전 키를 사용
+	retryAt time.Time
+	lastErr error
+}
+
+// NewJWKS creates a key set. source는 http(s) URL 또는 파일 경로이다.
+func NewJWKS(source string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the public key for kid, reloading the key set when it is stale
+// or when kid is unknown
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	stale := j.keys == nil || now.Sub(j.fetchedAt) >= j.refresh
+	_, known := j.keys[kid]
+	refetch := stale || (!known && kid != "" && now.Sub(j.fetchedAt) >= jwksRefetchInterval)
+	if refetch && !now.Before(j.retryAt) {
+		if err := j.load(ctx); err != nil {
+			// endpoint가 죽어 있으면 모든 요청이 lock을 잡고 timeout까지 기다리지 않도록 잠시 재시도를 멈춘다
+			slog.Warn("Failed to load JWKS", "source", j.source, "error", err)
+			j.retryAt = now.Add(jwksRefetchInterval)
+			j.lastErr = err
+		}
+	}
+	if j.keys == nil {
+		return nil, j.lastErr
+	}
+
+	// kid가 없는 토큰은 키가 하나일 때만 허용
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, nil
+		}
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+func (j *JWKS) load(ctx context.Context) error {
+	data, err := j.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// 지원하지 않는 키(OKP 등)나 잘못된 키 하나 때문에 나머지 키까지 버리지 않는다
+			slog.Warn("Skipping JWKS key", "kid", jwk.Kid, "kty", jwk.Kty, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.retryAt = time.Time{}
+	return nil
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		data, err := os.ReadFile(strings.TrimPrefix(j.source, "file://"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", j.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("JWKS endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64url value: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
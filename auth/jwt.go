@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"example.com/hello/tenant"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWTConfig configures JWT validation
+type JWTConfig struct {
+	Issuer        string
+	Audience      string
+	GroupsClaim   string
+	TenantClaim   string
+	DefaultScopes []string
+}
+
+// JWTValidator validates OIDC access tokens against a JWKS
+type JWTValidator struct {
+	jwks   *JWKS
+	config JWTConfig
+}
+
+// NewJWTValidator creates a new JWT validator
+func NewJWTValidator(jwks *JWKS, config JWTConfig) *JWTValidator {
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.TenantClaim == "" {
+		config.TenantClaim = "tenant"
+	}
+	return &JWTValidator{jwks: jwks, config: config}
+}
+
+// Authenticate validates token and returns the user principal
+func (v *JWTValidator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.config.Issuer))
+	}
+	if v.config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.config.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.jwks.Key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+
+	name, _ := claims["preferred_username"].(string)
+	if name == "" {
+		name = subject
+	}
+
+	tenantID, _ := claims[v.config.TenantClaim].(string)
+	if tenantID == "" {
+		tenantID = tenant.DefaultID
+	}
+
+	return &Principal{
+		ID:       "user:" + subject,
+		Name:     name,
+		TenantID: tenantID,
+		User:     subject,
+		Groups:   stringsClaim(claims[v.config.GroupsClaim]),
+		Scopes:   v.scopes(claims),
+	}, nil
+}
+
+// scopes maps the space separated "scope" claim onto known scopes
+func (v *JWTValidator) scopes(claims jwt.MapClaims) []string {
+	raw, _ := claims["scope"].(string)
+
+	var scopes []string
+	for _, scope := range strings.Fields(raw) {
+		if slices.Contains(ValidScopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return v.config.DefaultScopes
+	}
+	return scopes
+}
+
+func stringsClaim(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// Authenticator accepts either API keys or JWTs
+type Authenticator struct {
+	keys *KeyStore
+	jwt  *JWTValidator
+}
+
+// NewAuthenticator creates a new authenticator. jwt가 nil이면 API key만 허용한다.
+func NewAuthenticator(keys *KeyStore, jwt *JWTValidator) *Authenticator {
+	return &Authenticator{keys: keys, jwt: jwt}
+}
+
+// Authenticate dispatches credential to the JWT validator or the API key store
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if a.jwt != nil && strings.Count(credential, ".") == 2 {
+		return a.jwt.Authenticate(ctx, credential)
+	}
+	return a.keys.Authenticate(ctx, credential)
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaJWK(t *testing.T, kid string) (*rsa.PrivateKey, jsonWebKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// jwksFile writes keys as a local JWKS file
+func jwksFile(t *testing.T, keys ...jsonWebKey) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func claimsFor(subject string, expires time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":    subject,
+		"iss":    "https://issuer.example",
+		"aud":    "rag",
+		"exp":    expires.Unix(),
+		"scope":  "chat read unknown",
+		"groups": []string{"eng"},
+		"tenant": "acme",
+	}
+}
+
+func TestJWTValidator(t *testing.T) {
+	key, jwk := rsaJWK(t, "k1")
+	// 지원하지 않는 키와 잘못된 키는 건너뛰고 나머지 키로 검증
+	okp := jsonWebKey{Kty: "OKP", Kid: "ed", Crv: "Ed25519", X: "AAAA"}
+	broken := jsonWebKey{Kty: "RSA", Kid: "broken", N: "!!", E: "AQAB"}
+	validator := NewJWTValidator(NewJWKS(jwksFile(t, okp, broken, jwk), time.Hour), JWTConfig{
+		Issuer:   "https://issuer.example",
+		Audience: "rag",
+	})
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+
+	t.Run("valid", func(t *testing.T) {
+		p, err := validator.Authenticate(ctx, signToken(t, key, "k1", claimsFor("alice", future)))
+		if err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		if p.ID != "user:alice" || p.TenantID != "acme" || !slices.Equal(p.Groups, []string{"eng"}) || !slices.Equal(p.Scopes, []string{"chat", "read"}) {
+			t.Errorf("principal = %+v", p)
+		}
+	})
+
+	t.Run("unknown kid", func(t *testing.T) {
+		if _, err := validator.Authenticate(ctx, signToken(t, key, "k2", claimsFor("alice", future))); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("err = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		if _, err := validator.Authenticate(ctx, signToken(t, key, "k1", claimsFor("alice", time.Now().Add(-time.Minute)))); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("err = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("wrong signing key", func(t *testing.T) {
+		other, _ := rsaJWK(t, "k1")
+		if _, err := validator.Authenticate(ctx, signToken(t, other, "k1", claimsFor("alice", future))); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("err = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("no kid with one key", func(t *testing.T) {
+		if _, err := validator.Authenticate(ctx, signToken(t, key, "", claimsFor("alice", future))); err != nil {
+			t.Errorf("Authenticate: %v", err)
+		}
+	})
+}
+
+func TestJWTValidatorNoKidWithSeveralKeys(t *testing.T) {
+	key, jwk := rsaJWK(t, "k1")
+	_, other := rsaJWK(t, "k2")
+	validator := NewJWTValidator(NewJWKS(jwksFile(t, jwk, other), time.Hour), JWTConfig{})
+
+	if _, err := validator.Authenticate(context.Background(), signToken(t, key, "", claimsFor("alice", time.Now().Add(time.Hour)))); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+// TestJWKSBackoff checks that a failing endpoint is not refetched on every request
+func TestJWKSBackoff(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	jwks := NewJWKS(srv.URL, time.Hour)
+	for range 3 {
+		if _, err := jwks.Key(context.Background(), "k1"); err == nil {
+			t.Fatal("Key succeeded with a failing endpoint")
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("JWKS endpoint was fetched %d times, want 1", n)
+	}
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"example.com/hello/tenant"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const keyPrefix = "rag_"
+
+var (
+	ErrInvalidKey  = errors.New("invalid API key")
+	ErrKeyNotFound = errors.New("API key not found")
+)
+
+// APIKey represents a stored API key. 원본 키는 저장하지 않고 SHA-256 해시만 저장한다.
+type APIKey struct {
+	ID          int        `json:"id"`
+	TenantID    string     `json:"tenant_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	Collections []string   `json:"collections"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Principal converts the key into a request principal
+func (k *APIKey) Principal() *Principal {
+	return &Principal{
+		ID:          "key:" + strconv.Itoa(k.ID),
+		Name:        k.Name,
+		TenantID:    k.TenantID,
+		Scopes:      k.Scopes,
+		Collections: k.Collections,
+	}
+}
+
+// KeyStore stores hashed API keys in Postgres
+type KeyStore struct {
+	pool           *pgxpool.Pool
+	bootstrapAdmin string
+}
+
+// NewKeyStore creates a new API key store.
+// bootstrapAdmin이 설정되면 해당 키는 DB 조회 없이 admin으로 인증된다 (최초 키 발급용).
+func NewKeyStore(pool *pgxpool.Pool, bootstrapAdmin string) *KeyStore {
+	return &KeyStore{pool: pool, bootstrapAdmin: bootstrapAdmin}
+}
+
+// Migrate creates the api_keys table
+func (s *KeyStore) Migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS api_keys (
+            id           SERIAL PRIMARY KEY,
+            name         TEXT NOT NULL,
+            prefix       TEXT NOT NULL,
+            key_hash     TEXT NOT NULL UNIQUE,
+            scopes       TEXT[] NOT NULL,
+            collections  TEXT[] NOT NULL DEFAULT '{}',
+            created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+            revoked_at   TIMESTAMPTZ,
+            last_used_at TIMESTAMPTZ
+        )`,
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default'`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate api_keys table: %w", err)
+		}
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext key into a principal
+func (s *KeyStore) Authenticate(ctx context.Context, plain string) (*Principal, error) {
+	if s.bootstrapAdmin != "" && subtle.ConstantTimeCompare([]byte(plain), []byte(s.bootstrapAdmin)) == 1 {
+		return &Principal{
+			ID:            "bootstrap",
+			Name:          "bootstrap-admin",
+			TenantID:      tenant.DefaultID,
+			Scopes:        []string{ScopeAdmin},
+			PlatformAdmin: true,
+		}, nil
+	}
+
+	var key APIKey
+	err := s.pool.QueryRow(ctx, `
+        UPDATE api_keys SET last_used_at = now()
+        WHERE key_hash = $1 AND revoked_at IS NULL
+        RETURNING id, tenant_id, name, prefix, scopes, collections, created_at, revoked_at, last_used_at
+    `, hashKey(plain)).Scan(&key.ID, &key.TenantID, &key.Name, &key.Prefix, &key.Scopes, &key.Collections,
+		&key.CreatedAt, &key.RevokedAt, &key.LastUsedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+
+	return key.Principal(), nil
+}
+
+// Create issues a new key for tenantID and returns the plaintext value (한 번만 노출된다)
+func (s *KeyStore) Create(ctx context.Context, tenantID, name string, scopes, collections []string) (string, *APIKey, error) {
+	if err := validateScopes(scopes); err != nil {
+		return "", nil, err
+	}
+	if collections == nil {
+		collections = []string{}
+	}
+
+	plain, err := generateKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := APIKey{TenantID: tenantID, Name: name, Prefix: plain[:len(keyPrefix)+8], Scopes: scopes, Collections: collections}
+	err = s.pool.QueryRow(ctx, `
+        INSERT INTO api_keys (tenant_id, name, prefix, key_hash, scopes, collections)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at
+    `, key.TenantID, key.Name, key.Prefix, hashKey(plain), key.Scopes, key.Collections).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to insert API key: %w", err)
+	}
+
+	return plain, &key, nil
+}
+
+// Rotate replaces the secret of an active key, keeping its name, scopes and collections.
+// tenantID가 비어 있으면 모든 tenant의 키를 대상으로 한다 (platform admin).
+func (s *KeyStore) Rotate(ctx context.Context, tenantID string, id int) (string, *APIKey, error) {
+	plain, err := generateKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var key APIKey
+	err = s.pool.QueryRow(ctx, `
+        UPDATE api_keys SET key_hash = $2, prefix = $3
+        WHERE id = $1 AND revoked_at IS NULL AND ($4 = '' OR tenant_id = $4)
+        RETURNING id, tenant_id, name, prefix, scopes, collections, created_at, revoked_at, last_used_at
+    `, id, hashKey(plain), plain[:len(keyPrefix)+8], tenantID).Scan(&key.ID, &key.TenantID, &key.Name, &key.Prefix,
+		&key.Scopes, &key.Collections, &key.CreatedAt, &key.RevokedAt, &key.LastUsedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	return plain, &key, nil
+}
+
+// Revoke disables a key. tenantID가 비어 있으면 모든 tenant의 키를 대상으로 한다.
+func (s *KeyStore) Revoke(ctx context.Context, tenantID string, id int) error {
+	result, err := s.pool.Exec(ctx, `
+        UPDATE api_keys SET revoked_at = now()
+        WHERE id = $1 AND revoked_at IS NULL AND ($2 = '' OR tenant_id = $2)
+    `, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// List returns the keys of tenantID (비어 있으면 전체), including revoked ones
+func (s *KeyStore) List(ctx context.Context, tenantID string) ([]APIKey, error) {
+	rows, err := s.pool.Query(ctx, `
+        SELECT id, tenant_id, name, prefix, scopes, collections, created_at, revoked_at, last_used_at
+        FROM api_keys
+        WHERE $1 = '' OR tenant_id = $1
+        ORDER BY id
+    `, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(&key.ID, &key.TenantID, &key.Name, &key.Prefix, &key.Scopes, &key.Collections,
+			&key.CreatedAt, &key.RevokedAt, &key.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(ValidScopes, scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
+
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+func hashKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// API key scope
+const (
+	ScopeRead   = "read"
+	ScopeIngest = "ingest"
+	ScopeChat   = "chat"
+	ScopeAdmin  = "admin"
+)
+
+// ValidScopes lists every scope a key may be granted
+var ValidScopes = []string{ScopeRead, ScopeIngest, ScopeChat, ScopeAdmin}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	TenantID    string   `json:"tenant_id"`
+	User        string   `json:"user,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+	Scopes      []string `json:"scopes"`
+	Collections []string `json:"collections,omitempty"`
+	// PlatformAdmin은 모든 tenant를 관리할 수 있다 (bootstrap admin key)
+	PlatformAdmin bool `json:"platform_admin,omitempty"`
+}
+
+// HasScope reports whether the principal was granted scope. admin은 모든 scope를 포함한다.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// CanAccessCollection reports whether the principal may use collection.
+// Collections가 비어 있으면 모든 collection에 접근할 수 있다.
+func (p *Principal) CanAccessCollection(collection string) bool {
+	if len(p.Collections) == 0 || slices.Contains(p.Scopes, ScopeAdmin) {
+		return true
+	}
+	return slices.Contains(p.Collections, collection)
+}
+
+// IsAdmin reports whether the principal has the admin scope
+func (p *Principal) IsAdmin() bool {
+	return slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
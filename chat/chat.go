@@ -1,28 +1,17 @@
 package chat
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
+	"example.com/hello/logging"
+	"example.com/hello/prompt"
 	"example.com/hello/reranker"
+	"example.com/hello/tracing"
 )
 
 type Service struct {
-	apiURL string
-	model  string
-	client *http.Client
-}
-
-// ChatRequest represents the request to the chat API
-type ChatRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Stream   bool      `json:"stream"`
+	backend ChatModel
+	model   string
 }
 
 type Message struct {
@@ -30,46 +19,79 @@ type Message struct {
 	Content string `json:"content"`
 }
 
-// ChatResponse represents the response from the chat API
-type ChatResponse struct {
-	Model     string  `json:"model"`
-	CreatedAt string  `json:"created_at"`
-	Message   Message `json:"message"`
-	Done      bool    `json:"done"`
+// Usage represents the token counts reported by the chat API
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Total returns prompt + completion tokens
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Options overrides per-request settings (tenant별 모델/프롬프트)
+type Options struct {
+	Model        string
+	SystemPrompt string
+	// Template이 nil이면 기본 chat 템플릿 사용
+	Template *prompt.Template
+	Language string
+	// temperature, top_p, max tokens, stop, seed
+	Generation GenerateOptions
+	// Trace가 nil이 아니면 실제 보낸 메시지와 모델 응답을 기록한다 (debug 요청)
+	Trace *Trace
+}
+
+// Trace is the rendered request and raw response of one chat call
+type Trace struct {
+	Messages []Message        `json:"messages"`
+	Response GenerateResponse `json:"response"`
 }
 
-// NewService creates a new embedding service
+// NewService creates a new chat service backed by Ollama
 func NewService(apiURL, model string) *Service {
+	return NewServiceWithBackend(NewOllamaModel(apiURL), model)
+}
+
+// NewServiceWithBackend creates a chat service using backend (OpenAI 호환 서버 등)
+func NewServiceWithBackend(backend ChatModel, model string) *Service {
 	return &Service{
-		apiURL: apiURL,
-		model:  model,
-		client: &http.Client{},
+		backend: backend,
+		model:   model,
 	}
 }
 
 // Chat sends a message to the chat API with context documents
-func (s *Service) Chat(ctx context.Context, userQuestion string, contextDocuments []reranker.RankedDocument) (string, error) {
-	var sb strings.Builder
-
-	sb.WriteString("당신은 주어진 모든 참고 문서들을 종합하여 질문에 친절하게 안내하는 챗봇입니다.\n\n")
-	sb.WriteString("중요 규칙:\n")
-	sb.WriteString("1. 모든 참고 문서를 종합해 자연스러운 한두 문장으로 답변하세요.\n")
-	sb.WriteString("2. 필요한 정보만 간결하고 부드러운 말투로 안내하세요.\n\n")
-	sb.WriteString("3. 정보가 없으면 정중히 없다고 답하세요.\n\n")
-
-	if len(contextDocuments) > 0 {
-		sb.WriteString(fmt.Sprintf("=== 참고 문서 (총 %d개) ===\n", len(contextDocuments)))
-		for i, doc := range contextDocuments {
-			sb.WriteString(fmt.Sprintf("\n[문서 %d] (관련도: %.2f)\n", i+1, doc.Score))
-			sb.WriteString(doc.Content)
-			sb.WriteString("\n")
-		}
-		sb.WriteString("\n=== 모든 문서를 검토한 후 합해서 답변하세요 ===\n")
-	} else {
-		sb.WriteString("참고할 문서가 없습니다.\n")
+func (s *Service) Chat(ctx context.Context, userQuestion string, contextDocuments []reranker.RankedDocument, opts Options) (string, Usage, error) {
+	return s.ChatStream(ctx, userQuestion, contextDocuments, opts, nil)
+}
+
+// ChatStream is Chat with streaming: onDelta가 nil이 아니면 생성되는 답변 조각마다 호출된다.
+// onDelta가 error를 반환하면 (client 연결 종료 등) 생성을 중단하고, 그때까지 받은 답변과 사용량을 error와 함께 반환한다.
+func (s *Service) ChatStream(ctx context.Context, userQuestion string, contextDocuments []reranker.RankedDocument, opts Options, onDelta func(string) error) (answer string, usage Usage, err error) {
+	model := s.model
+	if opts.Model != "" {
+		model = opts.Model
 	}
 
-	systemPrompt := sb.String()
+	ctx, span := tracing.StartClientSpan(ctx, "example.com/hello/chat", "chat.Chat", model)
+	defer func() { tracing.End(span, err) }()
+	span.SetAttributes(tracing.AttrDocuments.Int(len(contextDocuments)))
+
+	// system 메시지는 prompt 템플릿으로 생성 (기본: prompt.DefaultChat)
+	tmpl := opts.Template
+	if tmpl == nil {
+		tmpl = prompt.DefaultFor(prompt.KindChat)
+	}
+	docs := make([]prompt.Document, len(contextDocuments))
+	for i, doc := range contextDocuments {
+		docs[i] = prompt.Document{Index: doc.Index, Content: doc.Content, Score: doc.Score}
+	}
+	systemPrompt, err := tmpl.Render(prompt.NewData(userQuestion, docs, opts.Language, opts.SystemPrompt))
+	if err != nil {
+		return "", Usage{}, err
+	}
 
 	// 메시지 구성
 	messages := []Message{
@@ -82,46 +104,65 @@ func (s *Service) Chat(ctx context.Context, userQuestion string, contextDocument
 			Content: userQuestion,
 		},
 	}
-	// 요청 데이터 생성
-	reqData := ChatRequest{
-		Model:    s.model,
+	logging.FromContext(ctx).Debug("sending chat request", "model", model, "documents", len(contextDocuments),
+		"template", tmpl.Ref(), logging.Text("system_prompt", systemPrompt), logging.Text("question", userQuestion))
+
+	resp, err := s.backend.Generate(ctx, GenerateRequest{
+		Model:    model,
 		Messages: messages,
-		Stream:   false,
+		Options:  opts.Generation,
+	}, onDelta)
+	if opts.Trace != nil {
+		opts.Trace.Messages = messages
+		opts.Trace.Response = resp
 	}
-	fmt.Println(reqData)
-	jsonData, err := json.Marshal(reqData)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		// stream이 중간에 끊기면 마지막 chunk의 사용량을 받지 못하므로 보낸 메시지와 받은 답변 길이로 추정
+		if resp.Content != "" && resp.Usage.Total() == 0 {
+			resp.Usage = estimateUsage(messages, resp.Content)
+		}
+		return resp.Content, resp.Usage, err
 	}
 
-	// HTTP 요청 생성
-	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	span.SetAttributes(
+		tracing.AttrPromptTokens.Int(resp.Usage.PromptTokens),
+		tracing.AttrCompletionTokens.Int(resp.Usage.CompletionTokens),
+	)
+
+	return resp.Content, resp.Usage, nil
+}
+
+// Complete sends messages as-is (query rewrite 등 RAG 답변 이외의 보조 호출용)
+func (s *Service) Complete(ctx context.Context, messages []Message, opts Options) (content string, usage Usage, err error) {
+	model := s.model
+	if opts.Model != "" {
+		model = opts.Model
 	}
 
-	// 헤더 설정
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("ngrok-skip-browser-warning", "true")
+	ctx, span := tracing.StartClientSpan(ctx, "example.com/hello/chat", "chat.Complete", model)
+	defer func() { tracing.End(span, err) }()
 
-	// 요청 전송
-	resp, err := s.client.Do(req)
+	resp, err := s.backend.Generate(ctx, GenerateRequest{
+		Model:    model,
+		Messages: messages,
+		Options:  opts.Generation,
+	}, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", Usage{}, err
 	}
-	defer resp.Body.Close()
 
-	// 상태 코드 확인
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
+	span.SetAttributes(
+		tracing.AttrPromptTokens.Int(resp.Usage.PromptTokens),
+		tracing.AttrCompletionTokens.Int(resp.Usage.CompletionTokens),
+	)
+	return resp.Content, resp.Usage, nil
+}
 
-	// 응답 파싱
-	var chatResp ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+// estimateUsage approximates token counts of a call whose usage was never reported (약 4 byte당 1 token)
+func estimateUsage(messages []Message, answer string) Usage {
+	prompt := 0
+	for _, m := range messages {
+		prompt += len(m.Content)
 	}
-
-	return chatResp.Message.Content, nil
+	return Usage{PromptTokens: (prompt + 3) / 4, CompletionTokens: (len(answer) + 3) / 4}
 }
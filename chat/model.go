@@ -0,0 +1,37 @@
+package chat
+
+import "context"
+
+// ChatModel is a chat completion backend (Ollama, OpenAI 호환 서버 등)
+type ChatModel interface {
+	// Generate returns the reply to messages. onDelta가 nil이 아니면 답변 조각을 stream으로 전달한다.
+	Generate(ctx context.Context, req GenerateRequest, onDelta func(string) error) (GenerateResponse, error)
+}
+
+// GenerateOptions are sampling options. nil/0 값은 backend 기본값을 사용한다.
+type GenerateOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// IsZero reports whether no option is set
+func (o GenerateOptions) IsZero() bool {
+	return o.Temperature == nil && o.TopP == nil && o.MaxTokens == 0 && len(o.Stop) == 0 && o.Seed == nil
+}
+
+// GenerateRequest is a backend-neutral chat request
+type GenerateRequest struct {
+	Model    string
+	Messages []Message
+	Options  GenerateOptions
+}
+
+// GenerateResponse is a backend-neutral chat response with normalized usage
+type GenerateResponse struct {
+	Content      string `json:"content"`
+	Usage        Usage  `json:"usage"`
+	FinishReason string `json:"finish_reason,omitempty"`
+}
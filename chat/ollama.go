@@ -0,0 +1,140 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/hello/metrics"
+	"example.com/hello/tracing"
+)
+
+// ChatRequest represents the request to the Ollama /api/chat API
+type ChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Options  *OllamaOptions `json:"options,omitempty"`
+}
+
+// OllamaOptions are the Ollama names of GenerateOptions
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// ChatResponse represents the response from the Ollama /api/chat API
+type ChatResponse struct {
+	Model      string  `json:"model"`
+	CreatedAt  string  `json:"created_at"`
+	Message    Message `json:"message"`
+	Done       bool    `json:"done"`
+	DoneReason string  `json:"done_reason,omitempty"`
+
+	// 토큰 사용량 (Ollama)
+	PromptEvalCount int `json:"prompt_eval_count,omitempty"`
+	EvalCount       int `json:"eval_count,omitempty"`
+}
+
+// OllamaModel calls the Ollama /api/chat API
+type OllamaModel struct {
+	apiURL string
+	client *http.Client
+}
+
+// NewOllamaModel creates an Ollama chat backend (apiURL 예: http://localhost:11434/api/chat)
+func NewOllamaModel(apiURL string) *OllamaModel {
+	return &OllamaModel{
+		apiURL: apiURL,
+		client: &http.Client{},
+	}
+}
+
+// Generate implements ChatModel
+func (m *OllamaModel) Generate(ctx context.Context, req GenerateRequest, onDelta func(string) error) (GenerateResponse, error) {
+	reqData := ChatRequest{
+		Model:    req.Model,
+		Messages: req.Messages,
+		Stream:   onDelta != nil,
+	}
+	if o := req.Options; !o.IsZero() {
+		reqData.Options = &OllamaOptions{
+			Temperature: o.Temperature,
+			TopP:        o.TopP,
+			NumPredict:  o.MaxTokens,
+			Stop:        o.Stop,
+			Seed:        o.Seed,
+		}
+	}
+
+	jsonData, err := json.Marshal(reqData)
+	if err != nil {
+		return GenerateResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// HTTP 요청 생성
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", m.apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return GenerateResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// 헤더 설정
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("ngrok-skip-browser-warning", "true")
+	tracing.Inject(ctx, httpReq)
+
+	// 요청 전송
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		metrics.RecordUpstreamError("chat", metrics.TransportReason(err))
+		return GenerateResponse{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 상태 코드 확인
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		metrics.RecordUpstreamError("chat", "status_"+strconv.Itoa(resp.StatusCode))
+		return GenerateResponse{}, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// 응답 파싱 (stream이면 줄 단위 JSON, 마지막 조각에 토큰 사용량이 있다)
+	var last ChatResponse
+	var sb strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk ChatResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			metrics.RecordUpstreamError("chat", "decode")
+			// 이미 받은 답변은 함께 반환 (quota, 감사 로그)
+			return GenerateResponse{Content: sb.String()}, fmt.Errorf("failed to decode response: %w", err)
+		}
+		sb.WriteString(chunk.Message.Content)
+		if onDelta != nil && chunk.Message.Content != "" {
+			if err := onDelta(chunk.Message.Content); err != nil {
+				return GenerateResponse{Content: sb.String()}, fmt.Errorf("failed to stream response: %w", err)
+			}
+		}
+		last = chunk
+		if onDelta == nil || chunk.Done {
+			break
+		}
+	}
+
+	return GenerateResponse{
+		Content: sb.String(),
+		Usage: Usage{
+			PromptTokens:     last.PromptEvalCount,
+			CompletionTokens: last.EvalCount,
+		},
+		FinishReason: last.DoneReason,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package chat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/hello/metrics"
+	"example.com/hello/tracing"
+)
+
+// openAIRequest is the OpenAI /v1/chat/completions request (vLLM, llama.cpp server, LM Studio)
+type openAIRequest struct {
+	Model         string    `json:"model"`
+	Messages      []Message `json:"messages"`
+	Stream        bool      `json:"stream"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// openAIResponse covers both a completion and a stream chunk
+type openAIResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		Delta        Message `json:"delta"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// OpenAIModel calls an OpenAI-compatible /v1/chat/completions API
+type OpenAIModel struct {
+	apiURL string
+	apiKey string
+	client *http.Client
+}
+
+// NewOpenAIModel creates an OpenAI-compatible chat backend
+// (apiURL 예: http://localhost:8000/v1/chat/completions, apiKey는 없으면 빈 값)
+func NewOpenAIModel(apiURL, apiKey string) *OpenAIModel {
+	return &OpenAIModel{
+		apiURL: apiURL,
+		apiKey: apiKey,
+		client: &http.Client{},
+	}
+}
+
+// Generate implements ChatModel
+func (m *OpenAIModel) Generate(ctx context.Context, req GenerateRequest, onDelta func(string) error) (GenerateResponse, error) {
+	reqData := openAIRequest{
+		Model:       req.Model,
+		Messages:    req.Messages,
+		Stream:      onDelta != nil,
+		Temperature: req.Options.Temperature,
+		TopP:        req.Options.TopP,
+		MaxTokens:   req.Options.MaxTokens,
+		Stop:        req.Options.Stop,
+		Seed:        req.Options.Seed,
+	}
+	if reqData.Stream {
+		// stream 마지막 chunk에 토큰 사용량을 받는다
+		reqData.StreamOptions = &struct {
+			IncludeUsage bool `json:"include_usage"`
+		}{IncludeUsage: true}
+	}
+
+	jsonData, err := json.Marshal(reqData)
+	if err != nil {
+		return GenerateResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", m.apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return GenerateResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+	tracing.Inject(ctx, httpReq)
+
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		metrics.RecordUpstreamError("chat", metrics.TransportReason(err))
+		return GenerateResponse{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		metrics.RecordUpstreamError("chat", "status_"+strconv.Itoa(resp.StatusCode))
+		return GenerateResponse{}, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if onDelta == nil {
+		var completion openAIResponse
+		if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+			metrics.RecordUpstreamError("chat", "decode")
+			return GenerateResponse{}, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(completion.Choices) == 0 {
+			metrics.RecordUpstreamError("chat", "decode")
+			return GenerateResponse{}, fmt.Errorf("response has no choices")
+		}
+		result := GenerateResponse{
+			Content:      completion.Choices[0].Message.Content,
+			FinishReason: completion.Choices[0].FinishReason,
+		}
+		if completion.Usage != nil {
+			result.Usage = Usage{PromptTokens: completion.Usage.PromptTokens, CompletionTokens: completion.Usage.CompletionTokens}
+		}
+		return result, nil
+	}
+
+	return readOpenAIStream(resp.Body, onDelta)
+}
+
+// readOpenAIStream reads server-sent events until "data: [DONE]"
+func readOpenAIStream(body io.Reader, onDelta func(string) error) (GenerateResponse, error) {
+	var result GenerateResponse
+	var sb strings.Builder
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			metrics.RecordUpstreamError("chat", "decode")
+			// 이미 받은 답변은 함께 반환 (quota, 감사 로그)
+			result.Content = sb.String()
+			return result, fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Usage != nil {
+			result.Usage = Usage{PromptTokens: chunk.Usage.PromptTokens, CompletionTokens: chunk.Usage.CompletionTokens}
+		}
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				result.FinishReason = choice.FinishReason
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			sb.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				result.Content = sb.String()
+				return result, fmt.Errorf("failed to stream response: %w", err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		metrics.RecordUpstreamError("chat", metrics.TransportReason(err))
+		result.Content = sb.String()
+		return result, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	result.Content = sb.String()
+	return result, nil
+}
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/goccy/go-yaml"
 	"github.com/joho/godotenv"
 )
 
@@ -20,43 +28,190 @@ type Config struct {
 	RerankerModel   string
 	LLMChatAPIURL   string
 	LLMChatModel    string
+	LLMChatBackend  string
+	LLMChatAPIKey   string
+	ListenAddr      string
+	ShutdownTimeout time.Duration
+	ShutdownDelay   time.Duration
+	LogLevel        string
+	LogDebug        bool
+	TraceExporter   string
+	ServiceName     string
+	AdminAPIKey     string
+	JWKSSource      string
+	JWTIssuer       string
+	JWTAudience     string
+	JWTGroupsClaim  string
+	JWTTenantClaim  string
+	JWTScopes       []string
+
+	RateLimitRPS      float64
+	RateLimitBurst    int
+	LLMMaxConcurrency int
+	LLMMaxQueue       int
+	LLMQueueTimeout   time.Duration
+	DailyTokenQuota   int
+
+	RerankThreshold float64
+	SearchTopK      int
+	SystemPrompt    string
+	QueryRewrites   int
+	MMREnabled      bool
+	MMRLambda       float64
+	MMRCandidates   int
+
+	// judge 모델 (비어 있으면 chat 설정을 그대로 사용)
+	JudgeAPIURL     string
+	JudgeModel      string
+	JudgeBackend    string
+	JudgeAPIKey     string
+	JudgeSampleRate float64
+
+	// chat 감사 로그 보관 기간 (0 = 삭제하지 않음)
+	AuditRetentionDays int
+
+	// File is the config file the values were loaded from (hot reload 감시 대상)
+	File string
 }
 
-func Load() (*Config, error) {
-	// .env 파일 로드
-	_ = godotenv.Load(".env.local")
-
-	return &Config{
-		DBHost:          os.Getenv("DB_HOST"),
-		DBPort:          os.Getenv("DB_PORT"),
-		DBUser:          os.Getenv("DB_USER"),
-		DBPassword:      os.Getenv("DB_PASSWORD"),
-		DBName:          os.Getenv("DB_NAME"),
-		DBSSLMode:       os.Getenv("DB_SSLMODE"),
-		EmbeddingAPIURL: os.Getenv("EMBEDDING_API_URL"),
-		EmbeddingModel:  os.Getenv("EMBEDDING_MODEL"),
-		RerankerAPIURL:  os.Getenv("RERANKER_API_URL"),
-		RerankerModel:   os.Getenv("RERANKER_MODEL"),
-		LLMChatAPIURL:   os.Getenv("LLMCHAT_API_URL"),
-		LLMChatModel:    os.Getenv("LLMCHAT_MODEL"),
-	}, nil
+// envLocalFile holds local overrides in KEY=value form
+const envLocalFile = ".env.local"
+
+// startupEnv is the process environment as it was before .env.local was applied.
+// reload 시 .env.local을 다시 읽어도 시작 시점의 환경변수가 계속 우선하도록 한 번만 저장한다.
+var startupEnv = sync.OnceValue(func() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+})
+
+// Load builds the effective configuration.
+// 우선순위: 기본값 < 설정 파일(YAML) < .env.local < 환경변수 < CLI flag
+func Load(args []string) (*Config, error) {
+	env := startupEnv()
+	// 다른 library(OTEL_* 등)도 읽을 수 있도록 process env에 반영 (이미 있는 값은 덮어쓰지 않음)
+	if err := godotenv.Load(envLocalFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load %s: %w", envLocalFile, err)
+	}
+	return load(args, env)
 }
 
-func (c *Config) GetDSN() string {
-	return fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		c.DBUser,
-		c.DBPassword,
-		c.DBHost,
-		c.DBPort,
-		c.DBName,
-		c.DBSSLMode,
-	)
+// load builds the configuration from env and a fresh read of .env.local
+func load(args []string, env map[string]string) (*Config, error) {
+	local, err := godotenv.Read(envLocalFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load %s: %w", envLocalFile, err)
+	}
+
+	cfg := &Config{}
+	fields := cfg.fields()
+
+	// 1. 기본값
+	for _, f := range fields {
+		if err := f.set(f.def); err != nil {
+			return nil, fmt.Errorf("invalid default for %s: %w", f.key, err)
+		}
+	}
+
+	// CLI flag 정의 (-config 포함)
+	configDefault := env["CONFIG_FILE"]
+	if configDefault == "" {
+		configDefault = local["CONFIG_FILE"]
+	}
+	flags := flag.NewFlagSet("hello", flag.ContinueOnError)
+	configFile := flags.String("config", configDefault, "path to a YAML config file")
+	flagValues := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		flagValues[f.key] = flags.String(f.flagName(), "", f.help)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// 2. 설정 파일
+	cfg.File = *configFile
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. .env.local, 4. 환경변수
+	for _, source := range []struct {
+		name   string
+		values map[string]string
+	}{{envLocalFile, local}, {"environment", env}} {
+		for _, f := range fields {
+			if value := source.values[f.key]; value != "" {
+				if err := f.set(value); err != nil {
+					return nil, fmt.Errorf("invalid %s in %s: %w", f.key, source.name, err)
+				}
+			}
+		}
+	}
+
+	// 5. CLI flag (명시적으로 지정된 것만)
+	var flagErr error
+	flags.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if fl.Name == f.flagName() && flagErr == nil {
+				if err := f.set(*flagValues[f.key]); err != nil {
+					flagErr = fmt.Errorf("invalid -%s: %w", fl.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile applies values from a YAML file whose keys are the lower-case env names
+// (예: db_host, llmchat_model)
+func (c *Config) loadFile(path string, fields []field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	known := make(map[string]field, len(fields))
+	for _, f := range fields {
+		known[f.fileKey()] = f
+	}
+
+	for key, value := range values {
+		f, ok := known[key]
+		if !ok {
+			return fmt.Errorf("unknown key %q in config file %s", key, path)
+		}
+		if err := f.set(fileValueString(value)); err != nil {
+			return fmt.Errorf("invalid %s in config file: %w", key, err)
+		}
+	}
+	return nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func (c *Config) GetDSN() string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.DBUser, c.DBPassword),
+		Host:     c.DBHost + ":" + c.DBPort,
+		Path:     "/" + c.DBName,
+		RawQuery: url.Values{"sslmode": {c.DBSSLMode}}.Encode(),
 	}
-	return defaultValue
+	return dsn.String()
 }
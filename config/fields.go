@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field describes one configuration value and where it can be set from
+type field struct {
+	key    string // 환경변수 이름 (파일 key와 flag 이름은 여기서 파생)
+	target any
+	def    string
+	secret bool
+	help   string
+}
+
+func (f field) fileKey() string {
+	return strings.ToLower(f.key)
+}
+
+func (f field) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(f.key), "_", "-")
+}
+
+// set parses value into the target according to its type
+func (f field) set(value string) error {
+	switch t := f.target.(type) {
+	case *string:
+		*t = value
+	case *int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		*t = n
+	case *float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return err
+		}
+		*t = n
+	case *bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		*t = b
+	case *time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		*t = d
+	case *[]string:
+		*t = strings.Fields(strings.ReplaceAll(value, ",", " "))
+	default:
+		return fmt.Errorf("unsupported config type %T", f.target)
+	}
+	return nil
+}
+
+// String formats the current value of the target
+func (f field) String() string {
+	switch t := f.target.(type) {
+	case *string:
+		return *t
+	case *int:
+		return strconv.Itoa(*t)
+	case *float64:
+		return strconv.FormatFloat(*t, 'g', -1, 64)
+	case *bool:
+		return strconv.FormatBool(*t)
+	case *time.Duration:
+		return t.String()
+	case *[]string:
+		return strings.Join(*t, " ")
+	default:
+		return fmt.Sprint(f.target)
+	}
+}
+
+// fields lists every configuration value with its default
+func (c *Config) fields() []field {
+	return []field{
+		{key: "DB_HOST", target: &c.DBHost, def: "localhost", help: "Postgres host"},
+		{key: "DB_PORT", target: &c.DBPort, def: "5432", help: "Postgres port"},
+		{key: "DB_USER", target: &c.DBUser, def: "postgres", help: "Postgres user"},
+		{key: "DB_PASSWORD", target: &c.DBPassword, def: "", secret: true, help: "Postgres password"},
+		{key: "DB_NAME", target: &c.DBName, def: "postgres", help: "Postgres database"},
+		{key: "DB_SSLMODE", target: &c.DBSSLMode, def: "disable", help: "Postgres sslmode"},
+		{key: "EMBEDDING_API_URL", target: &c.EmbeddingAPIURL, def: "http://localhost:11434/api/embeddings", help: "embedding API URL"},
+		{key: "EMBEDDING_MODEL", target: &c.EmbeddingModel, def: "bge-m3", help: "embedding model"},
+		{key: "RERANKER_API_URL", target: &c.RerankerAPIURL, def: "http://localhost:11434/api/generate", help: "reranker API URL"},
+		{key: "RERANKER_MODEL", target: &c.RerankerModel, def: "llama3-3b-rerank", help: "reranker model"},
+		{key: "LLMCHAT_API_URL", target: &c.LLMChatAPIURL, def: "http://localhost:11434/api/chat", help: "chat API URL"},
+		{key: "LLMCHAT_MODEL", target: &c.LLMChatModel, def: "gemma3:4b", help: "chat model"},
+		{key: "LLMCHAT_BACKEND", target: &c.LLMChatBackend, def: "ollama", help: "chat API type (ollama, openai)"},
+		{key: "LLMCHAT_API_KEY", target: &c.LLMChatAPIKey, def: "", secret: true, help: "API key for an OpenAI-compatible chat API"},
+		{key: "LISTEN_ADDR", target: &c.ListenAddr, def: ":8080", help: "HTTP listen address"},
+		{key: "SHUTDOWN_TIMEOUT", target: &c.ShutdownTimeout, def: "30s", help: "graceful shutdown drain timeout"},
+		{key: "SHUTDOWN_DELAY", target: &c.ShutdownDelay, def: "0s", help: "time /readyz reports not ready before the server stops accepting requests"},
+		{key: "LOG_LEVEL", target: &c.LogLevel, def: "info", help: "log level (debug, info, warn, error)"},
+		{key: "LOG_DEBUG", target: &c.LogDebug, def: "false", help: "log user content and vectors without redaction"},
+		{key: "OTEL_TRACES_EXPORTER", target: &c.TraceExporter, def: "none", help: "trace exporter (none, stdout, otlp)"},
+		{key: "OTEL_SERVICE_NAME", target: &c.ServiceName, def: "go-rag-chatbot", help: "service name for traces"},
+		{key: "ADMIN_API_KEY", target: &c.AdminAPIKey, def: "", secret: true, help: "bootstrap platform admin API key"},
+		{key: "JWT_JWKS", target: &c.JWKSSource, def: "", help: "JWKS URL or file path (enables JWT auth)"},
+		{key: "JWT_ISSUER", target: &c.JWTIssuer, def: "", help: "expected JWT issuer"},
+		{key: "JWT_AUDIENCE", target: &c.JWTAudience, def: "", help: "expected JWT audience"},
+		{key: "JWT_GROUPS_CLAIM", target: &c.JWTGroupsClaim, def: "groups", help: "JWT claim holding user groups"},
+		{key: "JWT_TENANT_CLAIM", target: &c.JWTTenantClaim, def: "tenant", help: "JWT claim holding the tenant ID"},
+		{key: "JWT_DEFAULT_SCOPES", target: &c.JWTScopes, def: "read chat", help: "scopes granted to JWTs without a scope claim"},
+		{key: "RATE_LIMIT_RPS", target: &c.RateLimitRPS, def: "2", help: "requests per second per client"},
+		{key: "RATE_LIMIT_BURST", target: &c.RateLimitBurst, def: "10", help: "rate limit burst per client"},
+		{key: "LLM_MAX_CONCURRENCY", target: &c.LLMMaxConcurrency, def: "2", help: "concurrent LLM requests"},
+		{key: "LLM_MAX_QUEUE", target: &c.LLMMaxQueue, def: "20", help: "requests waiting for an LLM slot"},
+		{key: "LLM_QUEUE_TIMEOUT", target: &c.LLMQueueTimeout, def: "30s", help: "max wait for an LLM slot"},
+		{key: "DAILY_TOKEN_QUOTA", target: &c.DailyTokenQuota, def: "0", help: "default daily token quota per tenant (0 = unlimited)"},
+		{key: "RERANK_THRESHOLD", target: &c.RerankThreshold, def: "0.6", help: "minimum rerank score for a document to reach the LLM"},
+		{key: "SEARCH_TOP_K", target: &c.SearchTopK, def: "3", help: "documents retrieved from the vector DB"},
+		{key: "QUERY_REWRITES", target: &c.QueryRewrites, def: "0", help: "extra queries generated from each question for retrieval (0 = disabled)"},
+		{key: "MMR_ENABLED", target: &c.MMREnabled, def: "false", help: "diversify search results with maximal marginal relevance"},
+		{key: "MMR_LAMBDA", target: &c.MMRLambda, def: "0.5", help: "MMR trade-off between relevance (1) and diversity (0)"},
+		{key: "MMR_CANDIDATES", target: &c.MMRCandidates, def: "20", help: "candidate pool searched before MMR selection"},
+		{key: "JUDGE_API_URL", target: &c.JudgeAPIURL, def: "", help: "chat API URL of the judge model (empty = LLMCHAT_API_URL)"},
+		{key: "JUDGE_MODEL", target: &c.JudgeModel, def: "", help: "judge model scoring answers (empty = LLMCHAT_MODEL)"},
+		{key: "JUDGE_BACKEND", target: &c.JudgeBackend, def: "", help: "judge API type (ollama, openai; empty = LLMCHAT_BACKEND)"},
+		{key: "JUDGE_API_KEY", target: &c.JudgeAPIKey, def: "", secret: true, help: "API key for an OpenAI-compatible judge API"},
+		{key: "JUDGE_SAMPLE_RATE", target: &c.JudgeSampleRate, def: "0", help: "fraction of live answers scored by the judge (0 = disabled)"},
+		{key: "AUDIT_RETENTION_DAYS", target: &c.AuditRetentionDays, def: "365", help: "days chat audit records are kept (0 = forever)"},
+		{key: "SYSTEM_PROMPT", target: &c.SystemPrompt, def: "", help: "chat system prompt (empty = built-in prompt)"},
+	}
+}
+
+// fileValueString converts a YAML value into the string form used by env vars
+func fileValueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
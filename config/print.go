@@ -0,0 +1,21 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Print writes the effective configuration as YAML, masking secrets
+func (c *Config) Print(w io.Writer) error {
+	for _, f := range c.fields() {
+		value := f.String()
+		if f.secret && value != "" {
+			value = "********"
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", f.fileKey(), strconv.Quote(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
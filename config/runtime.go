@@ -0,0 +1,232 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// maxReloadHistory is the number of reload events kept for the admin endpoint
+const maxReloadHistory = 20
+
+// Runtime holds the settings that may change without a restart
+type Runtime struct {
+	RerankThreshold float64 `json:"rerank_threshold"`
+	SearchTopK      int     `json:"search_top_k"`
+	SystemPrompt    string  `json:"system_prompt"`
+	QueryRewrites   int     `json:"query_rewrites"`
+	MMREnabled      bool    `json:"mmr_enabled"`
+	MMRLambda       float64 `json:"mmr_lambda"`
+	MMRCandidates   int     `json:"mmr_candidates"`
+	JudgeSampleRate float64 `json:"judge_sample_rate"`
+	RerankerModel   string  `json:"reranker_model"`
+	LLMChatModel    string  `json:"llmchat_model"`
+	RateLimitRPS    float64 `json:"rate_limit_rps"`
+	RateLimitBurst  int     `json:"rate_limit_burst"`
+	DailyTokenQuota int     `json:"daily_token_quota"`
+	LogLevel        string  `json:"log_level"`
+}
+
+// Runtime extracts the hot-reloadable part of the configuration
+func (c *Config) Runtime() Runtime {
+	return Runtime{
+		RerankThreshold: c.RerankThreshold,
+		SearchTopK:      c.SearchTopK,
+		SystemPrompt:    c.SystemPrompt,
+		QueryRewrites:   c.QueryRewrites,
+		MMREnabled:      c.MMREnabled,
+		MMRLambda:       c.MMRLambda,
+		MMRCandidates:   c.MMRCandidates,
+		JudgeSampleRate: c.JudgeSampleRate,
+		RerankerModel:   c.RerankerModel,
+		LLMChatModel:    c.LLMChatModel,
+		RateLimitRPS:    c.RateLimitRPS,
+		RateLimitBurst:  c.RateLimitBurst,
+		DailyTokenQuota: c.DailyTokenQuota,
+		LogLevel:        c.LogLevel,
+	}
+}
+
+// Snapshot is an immutable, versioned runtime configuration
+type Snapshot struct {
+	Version  int       `json:"version"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Runtime  Runtime   `json:"runtime"`
+}
+
+// ReloadEvent records one reload attempt
+type ReloadEvent struct {
+	Version int       `json:"version"`
+	At      time.Time `json:"at"`
+	Trigger string    `json:"trigger"`
+	Changed []string  `json:"changed,omitempty"`
+	Ignored []string  `json:"ignored,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Manager swaps the runtime configuration atomically on SIGHUP or config file change.
+// 요청은 시작 시점의 Snapshot을 사용하므로 reload 중에도 일관된 값을 본다.
+type Manager struct {
+	args    []string
+	env     map[string]string
+	last    *Config // 마지막으로 읽은 설정 (재시작이 필요한 변경은 한 번만 보고)
+	current atomic.Pointer[Snapshot]
+
+	mu        sync.Mutex
+	history   []ReloadEvent
+	listeners []func(Runtime)
+}
+
+// NewManager creates a manager from the config loaded at startup with args
+func NewManager(cfg *Config, args []string) *Manager {
+	m := &Manager{args: args, env: startupEnv(), last: cfg}
+	m.current.Store(&Snapshot{Version: 1, LoadedAt: time.Now(), Runtime: cfg.Runtime()})
+	m.history = []ReloadEvent{{Version: 1, At: time.Now(), Trigger: "startup"}}
+	return m
+}
+
+// Current returns the active runtime snapshot
+func (m *Manager) Current() *Snapshot {
+	return m.current.Load()
+}
+
+// History returns recent reload events, oldest first
+func (m *Manager) History() []ReloadEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ReloadEvent(nil), m.history...)
+}
+
+// OnChange registers fn to be called with the new runtime after every successful reload
+func (m *Manager) OnChange(fn func(Runtime)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Reload re-reads every config source and swaps in the new runtime settings.
+// DB, listen 주소 등 구조적인 값이 바뀐 경우 적용하지 않고 재시작이 필요하다고 기록한다.
+func (m *Manager) Reload(trigger string) (ReloadEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prev := m.current.Load()
+	event := ReloadEvent{Version: prev.Version, At: time.Now(), Trigger: trigger}
+
+	cfg, err := load(m.args, m.env)
+	if err != nil {
+		event.Error = err.Error()
+		m.record(event)
+		slog.Error("config reload failed, keeping current config", "trigger", trigger, "error", err)
+		return event, err
+	}
+
+	next := cfg.Runtime()
+	event.Changed = diffFields(prev.Runtime, next)
+	event.Ignored = structuralChanges(m.last, cfg)
+	m.last = cfg
+
+	if len(event.Changed) > 0 {
+		event.Version = prev.Version + 1
+		m.current.Store(&Snapshot{Version: event.Version, LoadedAt: event.At, Runtime: next})
+		for _, fn := range m.listeners {
+			fn(next)
+		}
+	}
+	m.record(event)
+
+	if len(event.Ignored) > 0 {
+		slog.Warn("config changes require a restart and were not applied", "fields", event.Ignored)
+	}
+	slog.Info("config reloaded", "trigger", trigger, "version", event.Version, "changed", event.Changed)
+	return event, nil
+}
+
+// Watch reloads on SIGHUP and when the config file or .env.local changes, until ctx is done
+func (m *Manager) Watch(ctx context.Context, pollInterval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastMod := m.modTimes()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			m.Reload("sighup")
+		case <-ticker.C:
+			if mod := m.modTimes(); !slices.EqualFunc(mod, lastMod, time.Time.Equal) {
+				lastMod = mod
+				m.Reload("file_change")
+			}
+		}
+	}
+}
+
+// modTimes returns the modification times of the watched files (없는 파일은 zero time)
+func (m *Manager) modTimes() []time.Time {
+	m.mu.Lock()
+	files := []string{envLocalFile, m.last.File}
+	m.mu.Unlock()
+
+	times := make([]time.Time, len(files))
+	for i, file := range files {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			times[i] = info.ModTime()
+		}
+	}
+	return times
+}
+
+func (m *Manager) record(event ReloadEvent) {
+	m.history = append(m.history, event)
+	if len(m.history) > maxReloadHistory {
+		m.history = m.history[len(m.history)-maxReloadHistory:]
+	}
+}
+
+// diffFields returns the json names of Runtime fields that differ
+func diffFields(a, b Runtime) []string {
+	var changed []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, va.Type().Field(i).Tag.Get("json"))
+		}
+	}
+	return changed
+}
+
+// structuralChanges returns config keys that changed but can only be applied by a restart.
+// EMBEDDING_MODEL도 여기에 속한다: 바꾸면 질의 vector가 저장된 문서 embedding과 호환되지 않아 재색인이 필요하다.
+func structuralChanges(prev, next *Config) []string {
+	runtimeKeys := map[string]bool{
+		"RERANK_THRESHOLD": true, "SEARCH_TOP_K": true, "SYSTEM_PROMPT": true, "QUERY_REWRITES": true,
+		"MMR_ENABLED": true, "MMR_LAMBDA": true, "MMR_CANDIDATES": true, "JUDGE_SAMPLE_RATE": true,
+		"RERANKER_MODEL": true, "LLMCHAT_MODEL": true,
+		"RATE_LIMIT_RPS": true, "RATE_LIMIT_BURST": true, "DAILY_TOKEN_QUOTA": true, "LOG_LEVEL": true,
+	}
+
+	var ignored []string
+	prevFields, nextFields := prev.fields(), next.fields()
+	for i, f := range prevFields {
+		if !runtimeKeys[f.key] && f.String() != nextFields[i].String() {
+			ignored = append(ignored, f.key)
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestReloadEnvLocal checks that edits to .env.local are applied on reload
+// while the startup environment and flags still win
+func TestReloadEnvLocal(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	yamlFile := filepath.Join(dir, "config.yaml")
+	writeFile(t, yamlFile, "search_top_k: 7\nrerank_threshold: 0.1\n")
+	writeFile(t, envLocalFile, "SEARCH_TOP_K=5\nSYSTEM_PROMPT=from file\nMMR_LAMBDA=0.3\n")
+
+	env := map[string]string{"MMR_LAMBDA": "0.9"}
+	args := []string{"-config", yamlFile, "-query-rewrites", "2"}
+	cfg, err := load(args, env)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.SearchTopK != 5 || cfg.RerankThreshold != 0.1 || cfg.MMRLambda != 0.9 || cfg.QueryRewrites != 2 {
+		t.Fatalf("layering: top_k=%d threshold=%v lambda=%v rewrites=%d", cfg.SearchTopK, cfg.RerankThreshold, cfg.MMRLambda, cfg.QueryRewrites)
+	}
+
+	m := &Manager{args: args, env: env, last: cfg}
+	m.current.Store(&Snapshot{Version: 1, Runtime: cfg.Runtime()})
+
+	writeFile(t, envLocalFile, "SEARCH_TOP_K=9\nSYSTEM_PROMPT=edited\nMMR_LAMBDA=0.1\nQUERY_REWRITES=4\n")
+	event, err := m.Reload("test")
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	got := m.Current().Runtime
+	if got.SearchTopK != 9 || got.SystemPrompt != "edited" {
+		t.Errorf(".env.local edit was not applied: top_k=%d prompt=%q", got.SearchTopK, got.SystemPrompt)
+	}
+	if got.MMRLambda != 0.9 || got.QueryRewrites != 2 {
+		t.Errorf(".env.local overrode env or flags: lambda=%v rewrites=%d", got.MMRLambda, got.QueryRewrites)
+	}
+	if !slices.Equal(event.Changed, []string{"search_top_k", "system_prompt"}) {
+		t.Errorf("Changed = %v", event.Changed)
+	}
+}
+
+// TestReloadReportsIgnoredOnce checks that a restart-only change is reported by one reload only
+func TestReloadReportsIgnoredOnce(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, envLocalFile, "DB_HOST=old-host\n")
+
+	cfg, err := load(nil, map[string]string{})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	m := &Manager{env: map[string]string{}, last: cfg}
+	m.current.Store(&Snapshot{Version: 1, Runtime: cfg.Runtime()})
+
+	writeFile(t, envLocalFile, "DB_HOST=new-host\n")
+	first, err := m.Reload("test")
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if !slices.Equal(first.Ignored, []string{"DB_HOST"}) {
+		t.Errorf("first reload Ignored = %v, want [DB_HOST]", first.Ignored)
+	}
+	second, err := m.Reload("test")
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(second.Ignored) != 0 {
+		t.Errorf("second reload reported %v again", second.Ignored)
+	}
+}
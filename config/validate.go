@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxQueryRewrites bounds the parallel searches and LLM output per question
+const maxQueryRewrites = 5
+
+// Validate checks URLs, ports and numeric ranges so that the server fails at startup
+// instead of on the first request
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.DBHost != "", "DB_HOST must not be empty")
+	check(validPort(c.DBPort), "DB_PORT must be a port number (1-65535), got %q", c.DBPort)
+	check(c.DBUser != "", "DB_USER must not be empty")
+	check(c.DBName != "", "DB_NAME must not be empty")
+	check(slices.Contains([]string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}, c.DBSSLMode),
+		"DB_SSLMODE is invalid: %q", c.DBSSLMode)
+
+	for _, u := range []struct{ key, value string }{
+		{"EMBEDDING_API_URL", c.EmbeddingAPIURL},
+		{"RERANKER_API_URL", c.RerankerAPIURL},
+		{"LLMCHAT_API_URL", c.LLMChatAPIURL},
+	} {
+		check(validHTTPURL(u.value), "%s must be an http(s) URL, got %q", u.key, u.value)
+	}
+	check(c.EmbeddingModel != "", "EMBEDDING_MODEL must not be empty")
+	check(c.RerankerModel != "", "RERANKER_MODEL must not be empty")
+	check(c.LLMChatModel != "", "LLMCHAT_MODEL must not be empty")
+	check(slices.Contains([]string{"ollama", "openai"}, c.LLMChatBackend),
+		"LLMCHAT_BACKEND must be one of ollama, openai, got %q", c.LLMChatBackend)
+
+	if c.JudgeAPIURL != "" {
+		check(validHTTPURL(c.JudgeAPIURL), "JUDGE_API_URL must be an http(s) URL, got %q", c.JudgeAPIURL)
+	}
+	check(slices.Contains([]string{"", "ollama", "openai"}, c.JudgeBackend),
+		"JUDGE_BACKEND must be one of ollama, openai, got %q", c.JudgeBackend)
+
+	_, port, err := net.SplitHostPort(c.ListenAddr)
+	check(err == nil && validPort(port), "LISTEN_ADDR must be host:port, got %q", c.ListenAddr)
+	check(c.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	check(c.ShutdownDelay >= 0, "SHUTDOWN_DELAY must not be negative")
+
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(c.LogLevel)),
+		"LOG_LEVEL must be one of debug, info, warn, error, got %q", c.LogLevel)
+	check(slices.Contains([]string{"none", "stdout", "otlp"}, c.TraceExporter),
+		"OTEL_TRACES_EXPORTER must be one of none, stdout, otlp, got %q", c.TraceExporter)
+
+	if c.JWKSSource != "" && (strings.HasPrefix(c.JWKSSource, "http://") || strings.HasPrefix(c.JWKSSource, "https://")) {
+		check(validHTTPURL(c.JWKSSource), "JWT_JWKS must be a valid URL or file path, got %q", c.JWKSSource)
+	}
+
+	check(c.RateLimitRPS > 0, "RATE_LIMIT_RPS must be positive")
+	check(c.RateLimitBurst >= 1, "RATE_LIMIT_BURST must be at least 1")
+	check(c.LLMMaxConcurrency >= 1, "LLM_MAX_CONCURRENCY must be at least 1")
+	check(c.LLMMaxQueue >= 0, "LLM_MAX_QUEUE must not be negative")
+	check(c.LLMQueueTimeout > 0, "LLM_QUEUE_TIMEOUT must be positive")
+	check(c.DailyTokenQuota >= 0, "DAILY_TOKEN_QUOTA must not be negative")
+	check(c.RerankThreshold >= 0 && c.RerankThreshold <= 1, "RERANK_THRESHOLD must be between 0 and 1")
+	check(c.SearchTopK >= 1 && c.SearchTopK <= 100, "SEARCH_TOP_K must be between 1 and 100")
+	check(c.MMRLambda >= 0 && c.MMRLambda <= 1, "MMR_LAMBDA must be between 0 and 1")
+	check(c.MMRCandidates >= c.SearchTopK && c.MMRCandidates <= 200, "MMR_CANDIDATES must be between SEARCH_TOP_K and 200")
+	check(c.AuditRetentionDays >= 0, "AUDIT_RETENTION_DAYS must not be negative")
+	check(c.JudgeSampleRate >= 0 && c.JudgeSampleRate <= 1, "JUDGE_SAMPLE_RATE must be between 0 and 1")
+	check(c.QueryRewrites >= 0 && c.QueryRewrites <= maxQueryRewrites, "QUERY_REWRITES must be between 0 and %d", maxQueryRewrites)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func validHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port >= 1 && port <= 65535
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"example.com/hello/config"
+	"example.com/hello/embedding"
+	"example.com/hello/ingest"
+	"example.com/hello/logging"
+	"example.com/hello/tenant"
+	"example.com/hello/vector"
+)
+
+const crawlUsage = `usage:
+  hello crawl -url https://wiki.internal/ [-depth 2] [-max-pages 100] [-delay 1s] [-collection name] [-tenant id] [-dry-run] [-- config flags]`
+
+// runCrawl crawls web pages into a collection
+func runCrawl(args []string) error {
+	flags := flag.NewFlagSet("crawl", flag.ContinueOnError)
+	seeds := flags.String("url", "", "comma separated seed URLs (only links on the same hosts are followed)")
+	depth := flags.Int("depth", ingest.DefaultMaxDepth, "how many links to follow from a seed (0 = seeds only)")
+	maxPages := flags.Int("max-pages", ingest.DefaultMaxPages, "maximum number of pages to fetch")
+	delay := flags.Duration("delay", 500*time.Millisecond, "minimum time between requests")
+	ignoreRobots := flags.Bool("ignore-robots", false, "do not read robots.txt")
+	userAgent := flags.String("user-agent", ingest.DefaultUserAgent, "User-Agent header, also matched against robots.txt")
+	tenantID := flags.String("tenant", tenant.DefaultID, "tenant the documents belong to")
+	collection := flags.String("collection", vector.DefaultCollection, "collection the documents are stored in")
+	chunkSize := flags.Int("chunk-size", ingest.DefaultChunkSize, "maximum chunk size in characters")
+	chunkOverlap := flags.Int("chunk-overlap", ingest.DefaultChunkOverlap, "characters repeated between chunks")
+	dryRun := flags.Bool("dry-run", false, "crawl and report without writing to the database")
+	jsonOut := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *seeds == "" {
+		return errors.New(crawlUsage)
+	}
+
+	// "--" 뒤의 인자는 서버와 같은 설정 flag
+	cfg, err := config.Load(flags.Args())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogDebug); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	db, err := vector.New(connectCtx, cfg.GetDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(connectCtx); err != nil {
+		return err
+	}
+	if err := checkTenant(connectCtx, db, *tenantID); err != nil {
+		return err
+	}
+
+	writer := ingest.NewWriter(db, embedding.NewService(cfg.EmbeddingAPIURL, cfg.EmbeddingModel))
+	writer.ChunkSize = *chunkSize
+	writer.ChunkOverlap = *chunkOverlap
+	ingester := ingest.NewWebIngester(db, ingest.NewCrawler(nil, *userAgent), writer)
+
+	report, err := ingester.Ingest(ctx, ingest.WebOptions{
+		CrawlOptions: ingest.CrawlOptions{
+			Seeds:        splitList(*seeds),
+			MaxDepth:     *depth,
+			MaxPages:     *maxPages,
+			Delay:        *delay,
+			IgnoreRobots: *ignoreRobots,
+		},
+		TenantID:   *tenantID,
+		Collection: *collection,
+		DryRun:     *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *jsonOut {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+	fmt.Printf("crawled %d pages: %d added, %d updated, %d deleted, %d unchanged, %d chunks\n",
+		report.Pages, len(report.Added), len(report.Updated), len(report.Deleted), report.Unchanged, report.Chunks)
+	for _, u := range report.Added {
+		fmt.Println("  +", u)
+	}
+	for _, u := range report.Updated {
+		fmt.Println("  ~", u)
+	}
+	for _, u := range report.Deleted {
+		fmt.Println("  -", u)
+	}
+	for _, s := range report.Skipped {
+		fmt.Printf("  skipped %s: %s\n", s.URL, s.Reason)
+	}
+	for _, e := range report.Errors {
+		fmt.Printf("  ! %s: %s\n", e.Path, e.Error)
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d pages failed", len(report.Errors))
+	}
+	return nil
+}
@@ -7,12 +7,26 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"example.com/hello/metrics"
+	"example.com/hello/tracing"
 )
 
+// defaultCacheSize is the number of query embeddings kept in memory
+const defaultCacheSize = 1000
+
+// maxBatchInputs is the number of texts sent in one batch request
+const maxBatchInputs = 64
+
 type Service struct {
-	apiURL string
-	model  string
-	client *http.Client
+	apiURL   string
+	batchURL string
+	model    atomic.Value // string, hot reload로 교체 가능
+	client   *http.Client
+	cache    *cache
 }
 
 // EmbeddingRequest represents the request to the embedding API
@@ -27,78 +41,175 @@ type EmbeddingResponse struct {
 	Embedding []float64 `json:"embedding"`
 }
 
+// EmbedBatchRequest is the request of the Ollama /api/embed endpoint (여러 text를 한 번에)
+type EmbedBatchRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbedBatchResponse is the response of the Ollama /api/embed endpoint
+type EmbedBatchResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
 // NewService creates a new embedding service
 func NewService(apiURL, model string) *Service {
-	return &Service{
-		apiURL: apiURL,
-		model:  model,
-		client: &http.Client{},
+	s := &Service{
+		apiURL:   apiURL,
+		batchURL: batchURL(apiURL),
+		client:   &http.Client{},
+		cache:    newCache(defaultCacheSize),
 	}
+	s.model.Store(model)
+	return s
 }
 
-// GenerateEmbedding generates embedding vector from text
+// Model returns the embedding model currently in use
+func (s *Service) Model() string {
+	return s.model.Load().(string)
+}
+
+// GenerateEmbedding generates embedding vector from a query text (캐시 사용)
 func (s *Service) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
-	// 요청 데이터 생성
-	reqData := EmbeddingRequest{
-		Model:  s.model,
-		Prompt: text,
-		Stream: false,
+	// 같은 질의문은 캐시된 embedding 사용 (모델이 바뀌면 다른 key)
+	model := s.Model()
+	key := model + "\x00" + text
+	if cached, ok := s.cache.get(key); ok {
+		metrics.RecordCache(true)
+		return cached, nil
 	}
+	metrics.RecordCache(false)
 
+	embedding32, err := s.generate(ctx, model, text)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.put(key, embedding32)
+	return embedding32, nil
+}
+
+func (s *Service) generate(ctx context.Context, model, text string) (embedding32 []float32, err error) {
+	ctx, span := tracing.StartClientSpan(ctx, "example.com/hello/embedding", "embedding.GenerateEmbedding", model)
+	defer func() { tracing.End(span, err) }()
+
+	var embResp EmbeddingResponse
+	if err := s.post(ctx, s.apiURL, EmbeddingRequest{Model: model, Prompt: text, Stream: false}, &embResp); err != nil {
+		return nil, err
+	}
+	return toFloat32(embResp.Embedding), nil
+}
+
+// generateBatch embeds texts with one /api/embed request
+func (s *Service) generateBatch(ctx context.Context, model string, texts []string) (embeddings [][]float32, err error) {
+	ctx, span := tracing.StartClientSpan(ctx, "example.com/hello/embedding", "embedding.GenerateBatchEmbeddings", model)
+	defer func() { tracing.End(span, err) }()
+	span.SetAttributes(tracing.AttrDocuments.Int(len(texts)))
+
+	var batchResp EmbedBatchResponse
+	if err := s.post(ctx, s.batchURL, EmbedBatchRequest{Model: model, Input: texts}, &batchResp); err != nil {
+		return nil, err
+	}
+	if len(batchResp.Embeddings) != len(texts) {
+		metrics.RecordUpstreamError("embedding", "decode")
+		return nil, fmt.Errorf("API returned %d embeddings for %d texts", len(batchResp.Embeddings), len(texts))
+	}
+	embeddings = make([][]float32, len(texts))
+	for i, emb := range batchResp.Embeddings {
+		embeddings[i] = toFloat32(emb)
+	}
+	return embeddings, nil
+}
+
+// post sends reqData as JSON to url and decodes the response into out
+func (s *Service) post(ctx context.Context, url string, reqData, out any) error {
 	jsonData, err := json.Marshal(reqData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// HTTP 요청 생성
-	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// 헤더 설정
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("ngrok-skip-browser-warning", "true")
+	tracing.Inject(ctx, req)
 
 	// 요청 전송
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		metrics.RecordUpstreamError("embedding", metrics.TransportReason(err))
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 상태 코드 확인
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		metrics.RecordUpstreamError("embedding", "status_"+strconv.Itoa(resp.StatusCode))
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	// 응답 파싱
-	var embResp EmbeddingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		metrics.RecordUpstreamError("embedding", "decode")
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
+	return nil
+}
 
-	// float64 -> float32 변환
-	embedding32 := make([]float32, len(embResp.Embedding))
-	for i, v := range embResp.Embedding {
+// toFloat32 converts an embedding from the API (float64 -> float32)
+func toFloat32(embedding []float64) []float32 {
+	embedding32 := make([]float32, len(embedding))
+	for i, v := range embedding {
 		embedding32[i] = float32(v)
 	}
+	return embedding32
+}
 
-	return embedding32, nil
+// GenerateDocumentEmbedding generates the embedding of a document to be stored.
+// 문서는 한 번만 embedding되므로 질의 캐시를 거치지 않는다 (ingest가 캐시를 밀어내지 않도록).
+func (s *Service) GenerateDocumentEmbedding(ctx context.Context, text string) ([]float32, error) {
+	return s.generate(ctx, s.Model(), text)
 }
 
-// GenerateBatchEmbeddings generates embeddings for multiple texts
+// GenerateBatchEmbeddings generates document embeddings for multiple texts (캐시 사용 안 함).
+// Ollama /api/embed로 maxBatchInputs개씩 한 번에 요청하고, batch endpoint를 알 수 없는 URL이면 한 건씩 요청한다.
 func (s *Service) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
-	embeddings := make([][]float32, len(texts))
+	embeddings := make([][]float32, 0, len(texts))
+
+	if s.batchURL == "" {
+		for i, text := range texts {
+			emb, err := s.GenerateDocumentEmbedding(ctx, text)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate embedding for text %d: %w", i, err)
+			}
+			embeddings = append(embeddings, emb)
+		}
+		return embeddings, nil
+	}
 
-	for i, text := range texts {
-		emb, err := s.GenerateEmbedding(ctx, text)
+	model := s.Model()
+	for start := 0; start < len(texts); start += maxBatchInputs {
+		end := min(start+maxBatchInputs, len(texts))
+		batch, err := s.generateBatch(ctx, model, texts[start:end])
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate embedding for text %d: %w", i, err)
+			return nil, fmt.Errorf("failed to generate embeddings for texts %d-%d: %w", start, end-1, err)
 		}
-		embeddings[i] = emb
+		embeddings = append(embeddings, batch...)
 	}
-
 	return embeddings, nil
 }
+
+// batchURL returns the /api/embed endpoint next to an Ollama /api/embeddings URL ("" otherwise)
+func batchURL(apiURL string) string {
+	base, ok := strings.CutSuffix(strings.TrimSuffix(apiURL, "/"), "/api/embeddings")
+	if !ok {
+		return ""
+	}
+	return base + "/api/embed"
+}
@@ -0,0 +1,78 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// ollama fakes the embedding endpoints; 각 text의 embedding은 [len(text)]
+func ollama(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/api/embed":
+			var req EmbedBatchRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			resp := EmbedBatchResponse{}
+			for _, text := range req.Input {
+				resp.Embeddings = append(resp.Embeddings, []float64{float64(len(text))})
+			}
+			json.NewEncoder(w).Encode(resp)
+		case "/api/embeddings", "/custom":
+			var req EmbeddingRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(EmbeddingResponse{Embedding: []float64{float64(len(req.Prompt))}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGenerateBatchEmbeddings(t *testing.T) {
+	texts := make([]string, maxBatchInputs+1)
+	for i := range texts {
+		texts[i] = string(make([]byte, i))
+	}
+
+	for _, tc := range []struct {
+		path  string
+		calls int32
+	}{
+		{"/api/embeddings", 2}, // /api/embed로 maxBatchInputs개씩
+		{"/custom", int32(len(texts))},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := ollama(t, &calls)
+
+			embeddings, err := NewService(srv.URL+tc.path, "model").GenerateBatchEmbeddings(context.Background(), texts)
+			if err != nil {
+				t.Fatalf("GenerateBatchEmbeddings: %v", err)
+			}
+			if n := calls.Load(); n != tc.calls {
+				t.Errorf("sent %d requests, want %d", n, tc.calls)
+			}
+			if len(embeddings) != len(texts) {
+				t.Fatalf("got %d embeddings, want %d", len(embeddings), len(texts))
+			}
+			for i, emb := range embeddings {
+				if len(emb) != 1 || emb[0] != float32(i) {
+					t.Errorf("embedding %d = %v, want [%d]", i, emb, i)
+				}
+			}
+		})
+	}
+}
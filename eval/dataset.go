@@ -0,0 +1,58 @@
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Example is one line of a golden dataset (JSONL)
+type Example struct {
+	ID              string `json:"id,omitempty"`
+	Question        string `json:"question"`
+	RelevantIDs     []int  `json:"relevant_ids"`
+	ReferenceAnswer string `json:"reference_answer,omitempty"`
+	// Collection이 비어 있으면 Runner의 collection 사용
+	Collection string `json:"collection,omitempty"`
+}
+
+// LoadDataset reads a JSONL dataset (빈 줄과 #으로 시작하는 줄은 무시)
+func LoadDataset(path string) ([]Example, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	var examples []Example
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var ex Example
+		if err := json.Unmarshal([]byte(text), &ex); err != nil {
+			return nil, fmt.Errorf("dataset line %d: %w", line, err)
+		}
+		if strings.TrimSpace(ex.Question) == "" {
+			return nil, fmt.Errorf("dataset line %d: question is empty", line)
+		}
+		if ex.ID == "" {
+			ex.ID = strconv.Itoa(line)
+		}
+		examples = append(examples, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("dataset %s is empty", path)
+	}
+	return examples, nil
+}
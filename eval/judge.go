@@ -0,0 +1,167 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/hello/chat"
+	"example.com/hello/prompt"
+	"example.com/hello/rag"
+)
+
+// Judge scores generated answers with a chat model (로컬 Ollama 모델도 사용 가능)
+type Judge struct {
+	chat    *chat.Service
+	model   string
+	prompts *prompt.Store
+}
+
+// NewJudge creates a judge that asks model through chatService.
+// 템플릿은 tenant/collection의 judge 바인딩, 없으면 기본 템플릿을 사용한다.
+func NewJudge(chatService *chat.Service, model string, prompts *prompt.Store) *Judge {
+	return &Judge{chat: chatService, model: model, prompts: prompts}
+}
+
+// Model returns the judge model name
+func (j *Judge) Model() string {
+	return j.model
+}
+
+// Sample is one answer to be judged
+type Sample struct {
+	TenantID   string
+	Collection string
+	Question   string
+	Answer     string
+	// Contexts는 LLM에 전달된 문서 (rerank 점수 순)
+	Contexts []string
+}
+
+// SampleFrom builds a sample from a pipeline answer
+func SampleFrom(r *rag.Retrieval, res *rag.Result) Sample {
+	sources := append(res.Sources[:0:0], res.Sources...)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Score > sources[j].Score })
+	contexts := make([]string, len(sources))
+	for i, doc := range sources {
+		contexts[i] = doc.Content
+	}
+	return Sample{
+		TenantID:   r.Request.Tenant.ID,
+		Collection: r.Request.Collection,
+		Question:   r.Request.Question,
+		Answer:     res.Answer,
+		Contexts:   contexts,
+	}
+}
+
+// Scores are judge scores between 0 and 1
+type Scores struct {
+	// Faithfulness: 답변의 주장 중 문서로 뒷받침되는 비율
+	Faithfulness float64 `json:"faithfulness"`
+	// AnswerRelevance: 답변이 질문에 얼마나 직접적으로 답하는지
+	AnswerRelevance float64 `json:"answer_relevance"`
+	// ContextPrecision: 유용한 문서가 상위에 있는지 (average precision)
+	ContextPrecision float64 `json:"context_precision"`
+	Reason           string  `json:"reason,omitempty"`
+}
+
+// Score asks the judge model to grade one answer
+func (j *Judge) Score(ctx context.Context, s Sample) (*Scores, chat.Usage, error) {
+	tmpl, err := j.prompts.Resolve(ctx, s.TenantID, s.Collection, prompt.KindJudge, "")
+	if err != nil {
+		return nil, chat.Usage{}, err
+	}
+	docs := make([]prompt.Document, len(s.Contexts))
+	for i, content := range s.Contexts {
+		docs[i] = prompt.Document{Index: i, Content: content}
+	}
+	data := prompt.NewData(s.Question, docs, "", "")
+	data.Answer = s.Answer
+	rendered, err := tmpl.Render(data)
+	if err != nil {
+		return nil, chat.Usage{}, err
+	}
+
+	// 같은 답변은 같은 점수를 받도록 temperature 0
+	temperature := 0.0
+	content, usage, err := j.chat.Complete(ctx, []chat.Message{{Role: "user", Content: rendered}}, chat.Options{
+		Model:      j.model,
+		Generation: chat.GenerateOptions{Temperature: &temperature},
+	})
+	if err != nil {
+		return nil, usage, err
+	}
+
+	scores, err := parseScores(content, len(s.Contexts))
+	if err != nil {
+		return nil, usage, err
+	}
+	return scores, usage, nil
+}
+
+// parseScores extracts the JSON object from the judge output
+func parseScores(content string, contexts int) (*Scores, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("judge returned no JSON object: %q", content)
+	}
+
+	var out struct {
+		Faithfulness     *float64  `json:"faithfulness"`
+		AnswerRelevance  *float64  `json:"answer_relevance"`
+		ContextRelevance []float64 `json:"context_relevance"`
+		Reason           string    `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse judge output: %w", err)
+	}
+	if out.Faithfulness == nil || out.AnswerRelevance == nil {
+		return nil, fmt.Errorf("judge output is missing faithfulness or answer_relevance: %q", content)
+	}
+
+	return &Scores{
+		Faithfulness:     clamp(*out.Faithfulness),
+		AnswerRelevance:  clamp(*out.AnswerRelevance),
+		ContextPrecision: averagePrecision(out.ContextRelevance, contexts),
+		Reason:           out.Reason,
+	}, nil
+}
+
+// averagePrecision scores the ranking of relevant contexts (RAGAS context precision).
+// judge가 문서 수보다 적게 답하면 나머지는 관련 없음으로 본다.
+func averagePrecision(relevance []float64, contexts int) float64 {
+	var hits int
+	var sum float64
+	for i := 0; i < contexts && i < len(relevance); i++ {
+		if relevance[i] >= 0.5 {
+			hits++
+			sum += float64(hits) / float64(i+1)
+		}
+	}
+	if hits == 0 {
+		return 0
+	}
+	return sum / float64(hits)
+}
+
+func clamp(v float64) float64 {
+	return min(max(v, 0), 1)
+}
+
+func (s *Scores) add(o Scores) {
+	s.Faithfulness += o.Faithfulness
+	s.AnswerRelevance += o.AnswerRelevance
+	s.ContextPrecision += o.ContextPrecision
+}
+
+func (s *Scores) divide(n int) {
+	if n == 0 {
+		return
+	}
+	s.Faithfulness /= float64(n)
+	s.AnswerRelevance /= float64(n)
+	s.ContextPrecision /= float64(n)
+}
@@ -0,0 +1,63 @@
+package eval
+
+import "math"
+
+// Metrics are ranking metrics averaged over examples
+type Metrics struct {
+	Recall float64 `json:"recall_at_k"`
+	MRR    float64 `json:"mrr"`
+	NDCG   float64 `json:"ndcg_at_k"`
+}
+
+// score computes recall@k, reciprocal rank and nDCG@k of ranked document IDs (binary relevance)
+func score(ranked []int, relevant []int, k int) Metrics {
+	if len(relevant) == 0 {
+		return Metrics{}
+	}
+	isRelevant := make(map[int]bool, len(relevant))
+	for _, id := range relevant {
+		isRelevant[id] = true
+	}
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+
+	var m Metrics
+	var hits int
+	var dcg float64
+	for i, id := range ranked {
+		if !isRelevant[id] {
+			continue
+		}
+		hits++
+		dcg += 1 / math.Log2(float64(i+2))
+		if m.MRR == 0 {
+			m.MRR = 1 / float64(i+1)
+		}
+	}
+	m.Recall = float64(hits) / float64(len(isRelevant))
+
+	var idcg float64
+	for i := 0; i < min(len(isRelevant), k); i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	if idcg > 0 {
+		m.NDCG = dcg / idcg
+	}
+	return m
+}
+
+func (m *Metrics) add(o Metrics) {
+	m.Recall += o.Recall
+	m.MRR += o.MRR
+	m.NDCG += o.NDCG
+}
+
+func (m *Metrics) divide(n int) {
+	if n == 0 {
+		return
+	}
+	m.Recall /= float64(n)
+	m.MRR /= float64(n)
+	m.NDCG /= float64(n)
+}
@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// Save writes report as indented JSON
+func Save(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// LoadReport reads a report written by Save
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// WriteSummary prints the summary of report
+func WriteSummary(w io.Writer, report *Report) {
+	s := report.Summary
+	fmt.Fprintf(w, "dataset: %s (%d examples, %d errors, k=%d)\n", report.Dataset, s.Examples, s.Errors, s.K)
+	if s.Unlabeled > 0 {
+		fmt.Fprintf(w, "%d examples without relevant_ids are not included in recall/mrr/ndcg\n", s.Unlabeled)
+	}
+	fmt.Fprintf(w, "%-18s %9s %9s\n", "", "retrieval", "rerank")
+	fmt.Fprintf(w, "%-18s %9.4f %9.4f\n", "recall@k", s.Retrieval.Recall, s.Rerank.Recall)
+	fmt.Fprintf(w, "%-18s %9.4f %9.4f\n", "mrr", s.Retrieval.MRR, s.Rerank.MRR)
+	fmt.Fprintf(w, "%-18s %9.4f %9.4f\n", "ndcg@k", s.Retrieval.NDCG, s.Rerank.NDCG)
+	fmt.Fprintf(w, "%-18s %9.4f\n", "rerank drop rate", s.RerankDropRate)
+	fmt.Fprintf(w, "%-18s %9.1f\n", "avg latency (ms)", s.AvgLatencyMS)
+	if j := s.Judge; j != nil {
+		fmt.Fprintf(w, "\njudge: %s (%d judged, %d errors)\n", j.Model, j.Examples, j.Errors)
+		fmt.Fprintf(w, "%-18s %9.4f\n", "faithfulness", j.Faithfulness)
+		fmt.Fprintf(w, "%-18s %9.4f\n", "answer relevance", j.AnswerRelevance)
+		fmt.Fprintf(w, "%-18s %9.4f\n", "context precision", j.ContextPrecision)
+	}
+}
+
+// WriteDiff prints config differences and metric deltas between two reports (b - a)
+func WriteDiff(w io.Writer, a, b *Report) {
+	fmt.Fprintf(w, "a: %s (%s)\nb: %s (%s)\n\n", a.Dataset, a.CreatedAt.Format("2006-01-02 15:04"), b.Dataset, b.CreatedAt.Format("2006-01-02 15:04"))
+
+	// 설정 차이
+	va, vb := reflect.ValueOf(a.Config), reflect.ValueOf(b.Config)
+	changed := false
+	for i := 0; i < va.NumField(); i++ {
+		fa, fb := va.Field(i).Interface(), vb.Field(i).Interface()
+		if !reflect.DeepEqual(fa, fb) {
+			if !changed {
+				fmt.Fprintln(w, "config changes:")
+				changed = true
+			}
+			fmt.Fprintf(w, "  %s: %v -> %v\n", va.Type().Field(i).Tag.Get("json"), fa, fb)
+		}
+	}
+	if !reflect.DeepEqual(a.Options, b.Options) {
+		fmt.Fprintf(w, "  options: %v -> %v\n", a.Options, b.Options)
+		changed = true
+	}
+	if changed {
+		fmt.Fprintln(w)
+	}
+
+	row := func(name string, x, y float64) {
+		fmt.Fprintf(w, "%-26s %8.4f %8.4f %+9.4f\n", name, x, y, y-x)
+	}
+	fmt.Fprintf(w, "%-26s %8s %8s %9s\n", "metric", "a", "b", "delta")
+	row("retrieval recall@k", a.Summary.Retrieval.Recall, b.Summary.Retrieval.Recall)
+	row("retrieval mrr", a.Summary.Retrieval.MRR, b.Summary.Retrieval.MRR)
+	row("retrieval ndcg@k", a.Summary.Retrieval.NDCG, b.Summary.Retrieval.NDCG)
+	row("rerank recall@k", a.Summary.Rerank.Recall, b.Summary.Rerank.Recall)
+	row("rerank mrr", a.Summary.Rerank.MRR, b.Summary.Rerank.MRR)
+	row("rerank ndcg@k", a.Summary.Rerank.NDCG, b.Summary.Rerank.NDCG)
+	row("rerank drop rate", a.Summary.RerankDropRate, b.Summary.RerankDropRate)
+	row("avg latency (ms)", a.Summary.AvgLatencyMS, b.Summary.AvgLatencyMS)
+	// judge 점수는 두 실행 모두 채점한 경우에만 비교
+	if ja, jb := a.Summary.Judge, b.Summary.Judge; ja != nil && jb != nil {
+		row("faithfulness", ja.Faithfulness, jb.Faithfulness)
+		row("answer relevance", ja.AnswerRelevance, jb.AnswerRelevance)
+		row("context precision", ja.ContextPrecision, jb.ContextPrecision)
+	}
+}
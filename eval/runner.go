@@ -0,0 +1,231 @@
+package eval
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"example.com/hello/config"
+	"example.com/hello/rag"
+	"example.com/hello/reranker"
+	"example.com/hello/tenant"
+	"example.com/hello/vector"
+)
+
+// Pipeline runs retrieval, rerank and generation (rag.Pipeline)
+type Pipeline interface {
+	Retrieve(ctx context.Context, req rag.Request) (*rag.Retrieval, error)
+	Generate(ctx context.Context, r *rag.Retrieval, onDelta func(string) error) (*rag.Result, error)
+}
+
+// Runner evaluates a Pipeline against a golden dataset
+type Runner struct {
+	pipeline   Pipeline
+	tenant     *tenant.Tenant
+	collection string
+	// Request는 모든 질문에 공통으로 적용할 옵션 (retrieval_mode, mmr 등)
+	Request rag.Request
+	// Judge가 있으면 답변까지 생성해 채점하고, Scores가 있으면 trend용으로 저장한다
+	Judge  *Judge
+	Scores *ScoreStore
+}
+
+// NewRunner creates a runner that asks questions as tenant (문서 ACL은 무시하고 전체 문서 대상)
+func NewRunner(pipeline Pipeline, t *tenant.Tenant, collection string) *Runner {
+	return &Runner{pipeline: pipeline, tenant: t, collection: collection}
+}
+
+// ExampleResult is the outcome of one example
+type ExampleResult struct {
+	ID          string  `json:"id"`
+	Question    string  `json:"question"`
+	RelevantIDs []int   `json:"relevant_ids"`
+	Retrieved   []int   `json:"retrieved_ids"`
+	Reranked    []int   `json:"reranked_ids"`
+	Retrieval   Metrics `json:"retrieval"`
+	Rerank      Metrics `json:"rerank"`
+	Dropped     int     `json:"dropped"`
+	LatencyMS   int64   `json:"latency_ms"`
+	Error       string  `json:"error,omitempty"`
+	Answer      string  `json:"answer,omitempty"`
+	Judge       *Scores `json:"judge,omitempty"`
+	JudgeError  string  `json:"judge_error,omitempty"`
+}
+
+// Summary aggregates the results of every successful example.
+// 검색 지표는 relevant_ids가 있는 예제만 평균한다 (Unlabeled는 제외된 예제 수).
+type Summary struct {
+	Examples       int           `json:"examples"`
+	Errors         int           `json:"errors"`
+	Unlabeled      int           `json:"unlabeled"`
+	K              int           `json:"k"`
+	Retrieval      Metrics       `json:"retrieval"`
+	Rerank         Metrics       `json:"rerank"`
+	RerankDropRate float64       `json:"rerank_drop_rate"`
+	AvgLatencyMS   float64       `json:"avg_latency_ms"`
+	Judge          *JudgeSummary `json:"judge,omitempty"`
+}
+
+// JudgeSummary averages the judge scores of every judged example
+type JudgeSummary struct {
+	Model    string `json:"model"`
+	Examples int    `json:"examples"`
+	Errors   int    `json:"errors"`
+	Scores
+}
+
+// Report is the stored result of one evaluation run
+type Report struct {
+	CreatedAt  time.Time       `json:"created_at"`
+	Dataset    string          `json:"dataset"`
+	Tenant     string          `json:"tenant"`
+	Collection string          `json:"collection"`
+	Config     config.Runtime  `json:"config"`
+	Options    map[string]any  `json:"options,omitempty"`
+	Summary    Summary         `json:"summary"`
+	Results    []ExampleResult `json:"results"`
+}
+
+// Run retrieves and reranks every example sequentially (GPU 하나를 공유하므로 순차 실행)
+func (r *Runner) Run(ctx context.Context, dataset string, examples []Example) (*Report, error) {
+	report := &Report{
+		CreatedAt:  time.Now().UTC(),
+		Dataset:    dataset,
+		Tenant:     r.tenant.ID,
+		Collection: r.collection,
+	}
+
+	var input, dropped int
+	var latency time.Duration
+	for i, ex := range examples {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		req := r.Request
+		req.Question = ex.Question
+		req.Tenant = r.tenant
+		req.Collection = r.collection
+		if ex.Collection != "" {
+			req.Collection = ex.Collection
+		}
+		req.Access = vector.Access{All: true}
+
+		result := ExampleResult{ID: ex.ID, Question: ex.Question, RelevantIDs: ex.RelevantIDs}
+		start := time.Now()
+		retrieval, err := r.pipeline.Retrieve(ctx, req)
+		elapsed := time.Since(start)
+		result.LatencyMS = elapsed.Milliseconds()
+		if err != nil {
+			result.Error = err.Error()
+			report.Summary.Errors++
+			report.Results = append(report.Results, result)
+			slog.Warn("eval example failed", "id", ex.ID, "error", err)
+			continue
+		}
+
+		// 실행 시점의 runtime 설정을 함께 저장해 두 실행을 비교할 수 있게 한다
+		report.Config = retrieval.Snapshot.Runtime
+		k := retrieval.Snapshot.Runtime.SearchTopK
+		report.Summary.K = k
+
+		for _, doc := range retrieval.Similar {
+			result.Retrieved = append(result.Retrieved, doc.ID)
+		}
+		result.Reranked = rankedIDs(retrieval.Sources)
+		result.Retrieval = score(result.Retrieved, ex.RelevantIDs, k)
+		result.Rerank = score(result.Reranked, ex.RelevantIDs, k)
+		result.Dropped = len(retrieval.Similar) - len(retrieval.Sources)
+
+		if r.Judge != nil {
+			r.judge(ctx, report, retrieval, &result)
+		}
+
+		report.Summary.Examples++
+		if len(ex.RelevantIDs) > 0 {
+			report.Summary.Retrieval.add(result.Retrieval)
+			report.Summary.Rerank.add(result.Rerank)
+		} else {
+			// 정답이 없는 예제는 0점으로 평균을 낮추지 않도록 지표에서 뺀다 (latency, judge는 집계)
+			report.Summary.Unlabeled++
+		}
+		input += len(retrieval.Similar)
+		dropped += result.Dropped
+		latency += elapsed
+		report.Results = append(report.Results, result)
+
+		slog.Info("eval example done", "n", i+1, "of", len(examples), "id", ex.ID,
+			"recall", result.Retrieval.Recall, "rerank_recall", result.Rerank.Recall)
+	}
+
+	n := report.Summary.Examples
+	labeled := n - report.Summary.Unlabeled
+	report.Summary.Retrieval.divide(labeled)
+	report.Summary.Rerank.divide(labeled)
+	if input > 0 {
+		report.Summary.RerankDropRate = float64(dropped) / float64(input)
+	}
+	if n > 0 {
+		report.Summary.AvgLatencyMS = float64(latency.Milliseconds()) / float64(n)
+	}
+	if j := report.Summary.Judge; j != nil {
+		j.divide(j.Examples)
+	}
+	return report, nil
+}
+
+// judge generates the answer of one example and scores it.
+// 답변 생성이나 채점이 실패해도 검색 지표는 그대로 집계한다.
+func (r *Runner) judge(ctx context.Context, report *Report, retrieval *rag.Retrieval, result *ExampleResult) {
+	summary := report.Summary.Judge
+	if summary == nil {
+		summary = &JudgeSummary{Model: r.Judge.Model()}
+		report.Summary.Judge = summary
+	}
+
+	answer, err := r.pipeline.Generate(ctx, retrieval, nil)
+	if err != nil {
+		result.JudgeError = err.Error()
+		summary.Errors++
+		return
+	}
+	result.Answer = answer.Answer
+
+	scores, _, err := r.Judge.Score(ctx, SampleFrom(retrieval, answer))
+	if err != nil {
+		result.JudgeError = err.Error()
+		summary.Errors++
+		slog.Warn("eval judge failed", "id", result.ID, "error", err)
+		return
+	}
+	result.Judge = scores
+	summary.Examples++
+	summary.add(*scores)
+
+	if r.Scores != nil {
+		err := r.Scores.Save(ctx, ScoreRecord{
+			TenantID:   r.tenant.ID,
+			Source:     SourceEval,
+			Collection: retrieval.Request.Collection,
+			Model:      answer.Model,
+			Prompt:     answer.Prompt,
+			JudgeModel: r.Judge.Model(),
+			Scores:     *scores,
+		})
+		if err != nil {
+			slog.Warn("failed to store eval judge scores", "id", result.ID, "error", err)
+		}
+	}
+}
+
+// rankedIDs orders reranked documents by score
+func rankedIDs(docs []reranker.RankedDocument) []int {
+	sorted := append([]reranker.RankedDocument(nil), docs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+	ids := make([]int, len(sorted))
+	for i, doc := range sorted {
+		ids[i] = doc.DocumentID
+	}
+	return ids
+}
@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"example.com/hello/rag"
+	"example.com/hello/ratelimit"
+)
+
+// judgeTimeout bounds one background judge call
+const judgeTimeout = 2 * time.Minute
+
+// Sampler judges a random fraction of live answers in the background.
+// judge 호출도 live 요청과 같은 LLM concurrency limiter slot을 잡고, 대기 중인 요청이 있으면 건너뛴다.
+type Sampler struct {
+	judge   *Judge
+	store   *ScoreStore
+	limiter *ratelimit.ConcurrencyLimiter
+	rate    func() float64
+	queue   chan sampled
+}
+
+type sampled struct {
+	sample Sample
+	record ScoreRecord
+}
+
+// NewSampler creates a sampler. rate는 매 답변마다 읽으므로 hot reload 값이 바로 반영된다
+func NewSampler(judge *Judge, store *ScoreStore, limiter *ratelimit.ConcurrencyLimiter, rate func() float64, queueSize int) *Sampler {
+	return &Sampler{
+		judge:   judge,
+		store:   store,
+		limiter: limiter,
+		rate:    rate,
+		queue:   make(chan sampled, queueSize),
+	}
+}
+
+// Observe is a rag.Pipeline result observer. 요청을 막지 않도록 queue가 가득 차면 버린다
+func (s *Sampler) Observe(_ context.Context, r *rag.Retrieval, res *rag.Result) {
+	if res.Incomplete {
+		return
+	}
+	rate := s.rate()
+	if rate <= 0 || rand.Float64() >= rate {
+		return
+	}
+	// LLM slot을 기다리는 요청이 있으면 채점보다 live 요청이 우선
+	if s.limiter.Waiting() > 0 {
+		slog.Debug("LLM queue is busy, skipping judge sample", "waiting", s.limiter.Waiting())
+		return
+	}
+
+	item := sampled{
+		sample: SampleFrom(r, res),
+		record: ScoreRecord{
+			TenantID:   r.Request.Tenant.ID,
+			Source:     SourceLive,
+			Collection: r.Request.Collection,
+			Model:      res.Model,
+			Prompt:     res.Prompt,
+			JudgeModel: s.judge.Model(),
+		},
+	}
+	select {
+	case s.queue <- item:
+	default:
+		slog.Warn("judge queue is full, dropping sampled answer")
+	}
+}
+
+// Run judges queued answers until ctx is done
+func (s *Sampler) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-s.queue:
+			s.score(ctx, item)
+		}
+	}
+}
+
+func (s *Sampler) score(ctx context.Context, item sampled) {
+	if s.limiter.Waiting() > 0 {
+		slog.Debug("LLM queue is busy, dropping sampled answer", "tenant", item.record.TenantID)
+		return
+	}
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		slog.Warn("no free LLM slot for judge, dropping sampled answer", "tenant", item.record.TenantID, "error", err)
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, judgeTimeout)
+	defer cancel()
+
+	scores, _, err := s.judge.Score(ctx, item.sample)
+	if err != nil {
+		slog.Warn("failed to judge sampled answer", "tenant", item.record.TenantID, "error", err)
+		return
+	}
+	item.record.Scores = *scores
+	if err := s.store.Save(ctx, item.record); err != nil {
+		slog.Warn("failed to store judge scores", "error", err)
+		return
+	}
+	slog.Debug("sampled answer judged", "tenant", item.record.TenantID,
+		"faithfulness", scores.Faithfulness, "answer_relevance", scores.AnswerRelevance, "context_precision", scores.ContextPrecision)
+}
@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Score sources
+const (
+	SourceLive = "live"
+	SourceEval = "eval"
+)
+
+// ScoreStore keeps judge scores in Postgres for trend reports
+type ScoreStore struct {
+	pool *pgxpool.Pool
+}
+
+// ScoreRecord is one judged answer
+type ScoreRecord struct {
+	TenantID   string `json:"tenant_id"`
+	Source     string `json:"source"`
+	Collection string `json:"collection"`
+	Model      string `json:"model"`
+	Prompt     string `json:"prompt"`
+	JudgeModel string `json:"judge_model"`
+	Scores     Scores `json:"scores"`
+}
+
+// TrendPoint is the daily average of judge scores
+type TrendPoint struct {
+	Day              time.Time `json:"day"`
+	Source           string    `json:"source"`
+	Count            int       `json:"count"`
+	Faithfulness     float64   `json:"faithfulness"`
+	AnswerRelevance  float64   `json:"answer_relevance"`
+	ContextPrecision float64   `json:"context_precision"`
+}
+
+// TrendFilter narrows a trend report. 빈 값은 조건 없음
+type TrendFilter struct {
+	Since      time.Time
+	Source     string
+	Collection string
+}
+
+// NewScoreStore creates a new judge score store
+func NewScoreStore(pool *pgxpool.Pool) *ScoreStore {
+	return &ScoreStore{pool: pool}
+}
+
+// Migrate creates the answer_scores table
+func (s *ScoreStore) Migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS answer_scores (
+            id                BIGSERIAL PRIMARY KEY,
+            tenant_id         TEXT NOT NULL,
+            source            TEXT NOT NULL,
+            collection        TEXT NOT NULL,
+            model             TEXT NOT NULL,
+            prompt            TEXT NOT NULL,
+            judge_model       TEXT NOT NULL,
+            faithfulness      DOUBLE PRECISION NOT NULL,
+            answer_relevance  DOUBLE PRECISION NOT NULL,
+            context_precision DOUBLE PRECISION NOT NULL,
+            reason            TEXT NOT NULL DEFAULT '',
+            created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+        )`,
+		`CREATE INDEX IF NOT EXISTS answer_scores_tenant_created_idx ON answer_scores (tenant_id, created_at)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate answer scores: %w", err)
+		}
+	}
+	return nil
+}
+
+// Save stores one judged answer
+func (s *ScoreStore) Save(ctx context.Context, rec ScoreRecord) error {
+	_, err := s.pool.Exec(ctx, `
+        INSERT INTO answer_scores (tenant_id, source, collection, model, prompt, judge_model,
+                                   faithfulness, answer_relevance, context_precision, reason)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `, rec.TenantID, rec.Source, rec.Collection, rec.Model, rec.Prompt, rec.JudgeModel,
+		rec.Scores.Faithfulness, rec.Scores.AnswerRelevance, rec.Scores.ContextPrecision, rec.Scores.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to save answer score: %w", err)
+	}
+	return nil
+}
+
+// Trend returns daily score averages of a tenant, oldest first
+func (s *ScoreStore) Trend(ctx context.Context, tenantID string, filter TrendFilter) ([]TrendPoint, error) {
+	rows, err := s.pool.Query(ctx, `
+        SELECT date_trunc('day', created_at) AS day, source, count(*),
+               avg(faithfulness), avg(answer_relevance), avg(context_precision)
+        FROM answer_scores
+        WHERE tenant_id = $1 AND created_at >= $2
+          AND ($3 = '' OR source = $3) AND ($4 = '' OR collection = $4)
+        GROUP BY day, source
+        ORDER BY day, source
+    `, tenantID, filter.Since, filter.Source, filter.Collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query answer score trend: %w", err)
+	}
+	defer rows.Close()
+
+	points := []TrendPoint{}
+	for rows.Next() {
+		var p TrendPoint
+		if err := rows.Scan(&p.Day, &p.Source, &p.Count, &p.Faithfulness, &p.AnswerRelevance, &p.ContextPrecision); err != nil {
+			return nil, fmt.Errorf("failed to scan answer score trend: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"example.com/hello/config"
+	"example.com/hello/embedding"
+	"example.com/hello/eval"
+	"example.com/hello/logging"
+	"example.com/hello/prompt"
+	"example.com/hello/rag"
+	"example.com/hello/reranker"
+	"example.com/hello/tenant"
+	"example.com/hello/vector"
+)
+
+const evalUsage = `usage:
+  hello eval run -dataset golden.jsonl -out result.json [-judge [-store]] [eval flags] [-- config flags]
+  hello eval diff a.json b.json`
+
+// runEval handles the eval subcommand
+func runEval(args []string) error {
+	if len(args) == 0 {
+		return errors.New(evalUsage)
+	}
+	switch args[0] {
+	case "run":
+		return runEvalRun(args[1:])
+	case "diff":
+		if len(args) != 3 {
+			return errors.New(evalUsage)
+		}
+		a, err := eval.LoadReport(args[1])
+		if err != nil {
+			return err
+		}
+		b, err := eval.LoadReport(args[2])
+		if err != nil {
+			return err
+		}
+		eval.WriteDiff(os.Stdout, a, b)
+		return nil
+	}
+	return errors.New(evalUsage)
+}
+
+// runEvalRun retrieves and reranks every dataset question through the real pipeline and stores the report.
+// -judge이면 답변까지 생성해 judge 모델로 채점한다.
+func runEvalRun(args []string) error {
+	flags := flag.NewFlagSet("eval run", flag.ContinueOnError)
+	dataset := flags.String("dataset", "", "golden dataset (JSONL: question, relevant_ids, reference_answer)")
+	out := flags.String("out", "", "write the JSON report to this file")
+	tenantID := flags.String("tenant", tenant.DefaultID, "tenant whose documents and settings are used")
+	collection := flags.String("collection", vector.DefaultCollection, "collection searched when an example has none")
+	mode := flags.String("mode", "", "retrieval mode (vector, hyde); empty = collection setting")
+	mmr := flags.String("mmr", "", "true/false to override MMR_ENABLED")
+	rewrites := flags.Int("rewrites", -1, "query rewrites (-1 = QUERY_REWRITES)")
+	judge := flags.Bool("judge", false, "generate answers and score them with the judge model (JUDGE_*)")
+	store := flags.Bool("store", false, "store judge scores in the database for trend reports")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *dataset == "" {
+		return errors.New(evalUsage)
+	}
+
+	// "--" 뒤의 인자는 서버와 같은 설정 flag
+	cfg, err := config.Load(flags.Args())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogDebug); err != nil {
+		return err
+	}
+
+	examples, err := eval.LoadDataset(*dataset)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	db, err := vector.New(connectCtx, cfg.GetDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(connectCtx); err != nil {
+		return err
+	}
+
+	tenantStore := tenant.NewStore(db.Pool())
+	promptStore := prompt.NewStore(db.Pool())
+	if err := tenantStore.Migrate(connectCtx); err != nil {
+		return err
+	}
+	if err := promptStore.Migrate(connectCtx); err != nil {
+		return err
+	}
+	scoreStore := eval.NewScoreStore(db.Pool())
+	if *store {
+		if err := scoreStore.Migrate(connectCtx); err != nil {
+			return err
+		}
+	}
+	t, err := tenantStore.Get(connectCtx, *tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant %s: %w", *tenantID, err)
+	}
+
+	pipeline := rag.NewPipeline(db,
+		embedding.NewService(cfg.EmbeddingAPIURL, cfg.EmbeddingModel),
+		reranker.NewService(cfg.RerankerAPIURL, cfg.RerankerModel),
+		newChatService(cfg),
+		config.NewManager(cfg, flags.Args()),
+		promptStore,
+	)
+
+	runner := eval.NewRunner(pipeline, t, *collection)
+	options := map[string]any{}
+	if *mode != "" {
+		runner.Request.RetrievalMode = *mode
+		options["retrieval_mode"] = *mode
+	}
+	if *mmr != "" {
+		enabled := *mmr == "true"
+		runner.Request.MMR = &enabled
+		options["mmr"] = enabled
+	}
+	if *rewrites >= 0 {
+		runner.Request.QueryRewrites = rewrites
+		options["query_rewrites"] = *rewrites
+	}
+	if *judge {
+		runner.Judge = eval.NewJudge(newJudgeService(cfg), judgeModel(cfg), promptStore)
+		if *store {
+			runner.Scores = scoreStore
+		}
+	}
+
+	report, err := runner.Run(ctx, *dataset, examples)
+	if err != nil {
+		return err
+	}
+	report.Options = options
+
+	eval.WriteSummary(os.Stdout, report)
+	if *out != "" {
+		if err := eval.Save(*out, report); err != nil {
+			return err
+		}
+		fmt.Println("report written to", *out)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package feedback
+
+import (
+	"slices"
+
+	"example.com/hello/eval"
+)
+
+// ToExamples converts feedback into eval dataset examples (한 답변당 최신 feedback 하나).
+// 근거 문서 중 잘못 지적되지 않은 문서를 relevant_ids로, 좋아요를 받은 답변은 reference_answer로 사용한다.
+// 싫어요를 받았는데 wrong_sources가 없으면 어떤 근거가 맞는지 알 수 없으므로,
+// relevant 문서가 하나도 남지 않는 feedback과 함께 사람이 정답 문서를 지정해야 하므로 제외한다.
+func ToExamples(entries []Entry) []eval.Example {
+	seen := make(map[string]bool, len(entries))
+	var examples []eval.Example
+	for _, e := range entries {
+		if seen[e.AnswerID] {
+			continue
+		}
+		seen[e.AnswerID] = true
+		if e.Rating == RatingDown && len(e.WrongSources) == 0 {
+			continue
+		}
+
+		var relevant []int
+		for _, id := range e.Trace.SourceIDs() {
+			if !slices.Contains(e.WrongSources, id) {
+				relevant = append(relevant, id)
+			}
+		}
+		if len(relevant) == 0 {
+			continue
+		}
+
+		ex := eval.Example{
+			ID:          e.AnswerID,
+			Question:    e.Trace.Question,
+			RelevantIDs: relevant,
+			Collection:  e.Trace.Collection,
+		}
+		if e.Rating == RatingUp {
+			ex.ReferenceAnswer = e.Trace.Answer
+		}
+		examples = append(examples, ex)
+	}
+	return examples
+}
@@ -0,0 +1,44 @@
+package feedback
+
+import (
+	"slices"
+	"testing"
+)
+
+func entry(answerID, rating string, wrong []int, sources ...int) Entry {
+	e := Entry{Feedback: Feedback{AnswerID: answerID, Rating: rating, WrongSources: wrong}}
+	e.Trace = Trace{AnswerID: answerID, Question: "q " + answerID, Answer: "a " + answerID}
+	for _, id := range sources {
+		e.Trace.Reranked = append(e.Trace.Reranked, Hit{DocumentID: id})
+	}
+	return e
+}
+
+func TestToExamples(t *testing.T) {
+	examples := ToExamples([]Entry{
+		entry("up", RatingUp, nil, 1, 2),
+		entry("down-marked", RatingDown, []int{3}, 3, 4),
+		entry("down-unmarked", RatingDown, nil, 5, 6),
+		entry("down-all-wrong", RatingDown, []int{7}, 7),
+		entry("up", RatingDown, []int{1}, 1, 2), // 같은 답변의 이전 feedback
+	})
+
+	got := map[string][]int{}
+	for _, ex := range examples {
+		got[ex.ID] = ex.RelevantIDs
+	}
+	want := map[string][]int{"up": {1, 2}, "down-marked": {4}}
+	if len(got) != len(want) {
+		t.Fatalf("examples = %v, want %v", got, want)
+	}
+	for id, ids := range want {
+		if !slices.Equal(got[id], ids) {
+			t.Errorf("%s relevant_ids = %v, want %v", id, got[id], ids)
+		}
+	}
+	for _, ex := range examples {
+		if (ex.ID == "up") != (ex.ReferenceAnswer != "") {
+			t.Errorf("%s reference_answer = %q", ex.ID, ex.ReferenceAnswer)
+		}
+	}
+}
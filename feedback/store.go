@@ -0,0 +1,210 @@
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/hello/auth"
+	"example.com/hello/logging"
+	"example.com/hello/rag"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ratings
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// traceTimeout bounds saving a trace after the answer was generated
+const traceTimeout = 5 * time.Second
+
+var ErrNotFound = errors.New("answer not found")
+
+// Store keeps answer traces and user feedback in Postgres
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+// Feedback is one user's rating of an answer
+type Feedback struct {
+	AnswerID string `json:"answer_id"`
+	Rating   string `json:"rating"`
+	Comment  string `json:"comment,omitempty"`
+	// WrongSources는 답변 근거로 잘못 사용된 문서 ID
+	WrongSources []int     `json:"wrong_sources,omitempty"`
+	Principal    string    `json:"principal,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Entry is feedback together with the trace of the answer
+type Entry struct {
+	Feedback
+	Trace Trace `json:"trace"`
+}
+
+// ListFilter narrows the feedback list. 빈 값은 조건 없음
+type ListFilter struct {
+	Rating     string
+	Collection string
+	Since      time.Time
+	Limit      int
+}
+
+// NewStore creates a new feedback store
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+// Migrate creates the trace and feedback tables
+func (s *Store) Migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS answer_traces (
+            answer_id  TEXT PRIMARY KEY,
+            tenant_id  TEXT NOT NULL,
+            collection TEXT NOT NULL,
+            trace      JSONB NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+        )`,
+		`CREATE TABLE IF NOT EXISTS answer_feedback (
+            answer_id     TEXT NOT NULL REFERENCES answer_traces (answer_id) ON DELETE CASCADE,
+            principal     TEXT NOT NULL,
+            rating        TEXT NOT NULL,
+            comment       TEXT NOT NULL DEFAULT '',
+            wrong_sources INTEGER[] NOT NULL DEFAULT '{}',
+            created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+            PRIMARY KEY (answer_id, principal)
+        )`,
+		`CREATE INDEX IF NOT EXISTS answer_traces_tenant_created_idx ON answer_traces (tenant_id, created_at)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate feedback store: %w", err)
+		}
+	}
+	return nil
+}
+
+// Observe is a rag.Pipeline result observer that stores the trace of every answer.
+// 응답이 끝난 뒤 바로 feedback을 보낼 수 있도록 요청 안에서 저장한다.
+func (s *Store) Observe(ctx context.Context, r *rag.Retrieval, res *rag.Result) {
+	p, _ := auth.FromContext(ctx)
+	trace := NewTrace(r, res, p)
+
+	// client가 연결을 끊어도 trace는 저장
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), traceTimeout)
+	defer cancel()
+	if err := s.SaveTrace(ctx, trace); err != nil {
+		logging.FromContext(ctx).Warn("failed to save answer trace", "answer_id", trace.AnswerID, "error", err)
+	}
+}
+
+// SaveTrace stores the trace of an answer
+func (s *Store) SaveTrace(ctx context.Context, t Trace) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trace: %w", err)
+	}
+	_, err = s.pool.Exec(ctx, `
+        INSERT INTO answer_traces (answer_id, tenant_id, collection, trace, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (answer_id) DO NOTHING
+    `, t.AnswerID, t.TenantID, t.Collection, data, t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save trace: %w", err)
+	}
+	return nil
+}
+
+// GetTrace returns the trace of an answer of tenantID
+func (s *Store) GetTrace(ctx context.Context, tenantID, answerID string) (*Trace, error) {
+	var data []byte
+	err := s.pool.QueryRow(ctx, `
+        SELECT trace FROM answer_traces WHERE tenant_id = $1 AND answer_id = $2
+    `, tenantID, answerID).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trace: %w", err)
+	}
+
+	var t Trace
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse trace: %w", err)
+	}
+	return &t, nil
+}
+
+// Submit stores feedback. 같은 사용자가 다시 보내면 이전 feedback을 덮어쓴다
+func (s *Store) Submit(ctx context.Context, f Feedback) (*Feedback, error) {
+	if f.WrongSources == nil {
+		f.WrongSources = []int{}
+	}
+	err := s.pool.QueryRow(ctx, `
+        INSERT INTO answer_feedback (answer_id, principal, rating, comment, wrong_sources)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (answer_id, principal) DO UPDATE SET
+            rating = EXCLUDED.rating,
+            comment = EXCLUDED.comment,
+            wrong_sources = EXCLUDED.wrong_sources,
+            created_at = now()
+        RETURNING created_at
+    `, f.AnswerID, f.Principal, f.Rating, f.Comment, f.WrongSources).Scan(&f.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save feedback: %w", err)
+	}
+	return &f, nil
+}
+
+// List returns feedback of tenantID with the traces of the answers, newest first
+func (s *Store) List(ctx context.Context, tenantID string, filter ListFilter) ([]Entry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	rows, err := s.pool.Query(ctx, `
+        SELECT f.answer_id, f.principal, f.rating, f.comment, f.wrong_sources, f.created_at, t.trace
+        FROM answer_feedback f
+        JOIN answer_traces t ON t.answer_id = f.answer_id
+        WHERE t.tenant_id = $1 AND f.created_at >= $2
+          AND ($3 = '' OR f.rating = $3) AND ($4 = '' OR t.collection = $4)
+        ORDER BY f.created_at DESC
+        LIMIT $5
+    `, tenantID, filter.Since, filter.Rating, filter.Collection, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var e Entry
+		var data []byte
+		if err := rows.Scan(&e.AnswerID, &e.Principal, &e.Rating, &e.Comment, &e.WrongSources, &e.CreatedAt, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		if err := json.Unmarshal(data, &e.Trace); err != nil {
+			return nil, fmt.Errorf("failed to parse trace: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Purge deletes traces of tenantID created before cutoff, with their feedback (감사 로그와 같은 보관 기간 적용)
+func (s *Store) Purge(ctx context.Context, tenantID string, cutoff time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+        DELETE FROM answer_traces WHERE tenant_id = $1 AND created_at < $2
+    `, tenantID, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge answer traces: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
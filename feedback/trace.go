@@ -0,0 +1,78 @@
+package feedback
+
+import (
+	"sort"
+	"time"
+
+	"example.com/hello/auth"
+	"example.com/hello/rag"
+)
+
+// Trace records how one answer was produced, so that feedback can be traced back to the pipeline
+type Trace struct {
+	AnswerID   string `json:"answer_id"`
+	TenantID   string `json:"tenant_id"`
+	Collection string `json:"collection"`
+	// Principal은 질문한 API key 또는 JWT subject의 ID
+	Principal string `json:"principal,omitempty"`
+	Question  string `json:"question"`
+	// Queries는 검색에 사용한 query (원래 질문 + rewrite 결과)
+	Queries       []string  `json:"queries"`
+	Mode          string    `json:"mode"`
+	Retrieved     []Hit     `json:"retrieved"`
+	Reranked      []Hit     `json:"reranked"`
+	Prompt        string    `json:"prompt"`
+	RerankPrompt  string    `json:"rerank_prompt"`
+	Model         string    `json:"model"`
+	ConfigVersion int       `json:"config_version"`
+	Answer        string    `json:"answer"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Hit is a document returned by search (Distance) or rerank (Score)
+type Hit struct {
+	DocumentID int      `json:"document_id"`
+	Distance   *float64 `json:"distance,omitempty"`
+	Score      *float64 `json:"score,omitempty"`
+}
+
+// NewTrace builds the trace of a generated answer
+func NewTrace(r *rag.Retrieval, res *rag.Result, p *auth.Principal) Trace {
+	t := Trace{
+		AnswerID:      res.ID,
+		TenantID:      r.Request.Tenant.ID,
+		Collection:    r.Request.Collection,
+		Question:      r.Request.Question,
+		Queries:       r.Queries,
+		Mode:          r.Mode,
+		Prompt:        res.Prompt,
+		RerankPrompt:  r.RerankTemplate.Ref(),
+		Model:         res.Model,
+		ConfigVersion: r.Snapshot.Version,
+		Answer:        res.Answer,
+	}
+	if p != nil {
+		t.Principal = p.ID
+	}
+	for _, doc := range r.Similar {
+		distance := doc.Distance
+		t.Retrieved = append(t.Retrieved, Hit{DocumentID: doc.ID, Distance: &distance})
+	}
+	// rerank 점수 순으로 기록
+	sources := append(res.Sources[:0:0], res.Sources...)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Score > sources[j].Score })
+	for _, doc := range sources {
+		score := doc.Score
+		t.Reranked = append(t.Reranked, Hit{DocumentID: doc.DocumentID, Score: &score})
+	}
+	return t
+}
+
+// SourceIDs returns the IDs of the documents given to the chat model
+func (t *Trace) SourceIDs() []int {
+	ids := make([]int, len(t.Reranked))
+	for i, hit := range t.Reranked {
+		ids[i] = hit.DocumentID
+	}
+	return ids
+}
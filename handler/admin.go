@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"example.com/hello/config"
+	"example.com/hello/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	runtime *config.Manager
+}
+
+func NewAdminHandler(runtime *config.Manager) *AdminHandler {
+	return &AdminHandler{runtime: runtime}
+}
+
+// GetConfig handles GET /admin/config
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"current": h.runtime.Current(),
+		"history": h.runtime.History(),
+	})
+}
+
+// ReloadConfig handles POST /admin/config/reload
+func (h *AdminHandler) ReloadConfig(c *gin.Context) {
+	event, err := h.runtime.Reload("admin_api")
+	if err != nil {
+		// 잘못된 설정이면 기존 설정을 그대로 유지한다
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "event": event})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"current": h.runtime.Current(),
+		"event":   event,
+	})
+}
+
+// GetLogLevel handles GET /admin/log-level
+func (h *AdminHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"level": logging.Level(),
+		"debug": logging.Debug(),
+	})
+}
+
+// SetLogLevel handles PUT /admin/log-level
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	var req struct {
+		Level string `json:"level" binding:"required"`
+		Debug *bool  `json:"debug"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := logging.SetLevel(req.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Debug != nil {
+		logging.SetDebug(*req.Debug)
+	}
+
+	logging.FromContext(c.Request.Context()).Warn("log level changed", "level", logging.Level(), "debug", logging.Debug())
+	c.JSON(http.StatusOK, gin.H{
+		"level": logging.Level(),
+		"debug": logging.Debug(),
+	})
+}
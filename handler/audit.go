@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/hello/audit"
+	"example.com/hello/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	store *audit.Store
+}
+
+func NewAuditHandler(store *audit.Store) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// SearchAudit handles GET /admin/audit
+func (h *AuditHandler) SearchAudit(c *gin.Context) {
+	var q audit.Query
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	records, err := h.store.Search(c.Request.Context(), tenantFor(c).ID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"records": records,
+		"count":   len(records),
+	}
+	// 다음 페이지는 before_id=next_before_id
+	if len(records) > 0 {
+		response["next_before_id"] = records[len(records)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+var auditCSVHeader = []string{
+	"id", "created_at", "answer_id", "tenant_id", "principal_id", "principal_name", "user", "collection",
+	"question", "answer", "sources", "model", "prompt", "latency_ms", "prompt_tokens", "completion_tokens",
+	"incomplete", "error",
+}
+
+// ExportAudit handles GET /admin/audit/export?format=jsonl|csv.
+// 전체 결과를 메모리에 올리지 않고 DB에서 읽는 대로 내보낸다.
+func (h *AuditHandler) ExportAudit(c *gin.Context) {
+	var q audit.Query
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := c.DefaultQuery("format", "jsonl")
+	if format != "jsonl" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be jsonl or csv"})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	var write func(audit.Record) error
+	var flush func() error
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		w := csv.NewWriter(c.Writer)
+		if err := w.Write(auditCSVHeader); err != nil {
+			return
+		}
+		write = func(rec audit.Record) error { return w.Write(auditCSVRow(rec)) }
+		flush = func() error { w.Flush(); return w.Error() }
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(c.Writer)
+		write = func(rec audit.Record) error { return enc.Encode(rec) }
+		flush = func() error { return nil }
+	}
+
+	// header를 이미 보냈으므로 중간 실패는 로그로만 남는다
+	if err := h.store.Each(c.Request.Context(), tenantFor(c).ID, q, write); err != nil {
+		logging.FromContext(c.Request.Context()).Error("audit export failed", "error", err)
+	}
+	if err := flush(); err != nil {
+		logging.FromContext(c.Request.Context()).Error("audit export failed", "error", err)
+	}
+}
+
+// auditCSVRow formats a record; sources는 "document_id@version" 목록
+func auditCSVRow(rec audit.Record) []string {
+	sources := make([]string, len(rec.Sources))
+	for i, s := range rec.Sources {
+		sources[i] = strconv.Itoa(s.DocumentID) + "@" + s.Version
+	}
+	return []string{
+		strconv.FormatInt(rec.ID, 10),
+		rec.CreatedAt.UTC().Format(time.RFC3339),
+		rec.AnswerID,
+		rec.TenantID,
+		rec.PrincipalID,
+		csvText(rec.PrincipalName),
+		csvText(rec.User),
+		csvText(rec.Collection),
+		csvText(rec.Question),
+		csvText(rec.Answer),
+		strings.Join(sources, " "),
+		csvText(rec.Model),
+		csvText(rec.Prompt),
+		strconv.FormatInt(rec.LatencyMS, 10),
+		strconv.Itoa(rec.PromptTokens),
+		strconv.Itoa(rec.CompletionTokens),
+		strconv.FormatBool(rec.Incomplete),
+		csvText(rec.Error),
+	}
+}
+
+// csvText escapes user-controlled text so spreadsheets do not run it as a formula
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"testing"
+
+	"example.com/hello/audit"
+)
+
+func TestAuditCSVRowEscapesFormulas(t *testing.T) {
+	row := auditCSVRow(audit.Record{
+		User:     "@admin",
+		Question: "=HYPERLINK(\"http://evil\")",
+		Answer:   "-1+2",
+		Prompt:   "chat:default",
+		Error:    "+cmd",
+	})
+
+	want := map[string]string{
+		"user":     "'@admin",
+		"question": "'=HYPERLINK(\"http://evil\")",
+		"answer":   "'-1+2",
+		"prompt":   "chat:default",
+		"error":    "'+cmd",
+	}
+	for i, name := range auditCSVHeader {
+		if w, ok := want[name]; ok && row[i] != w {
+			t.Errorf("%s = %q, want %q", name, row[i], w)
+		}
+	}
+}
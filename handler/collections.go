@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	database "example.com/hello/vector"
+	"github.com/gin-gonic/gin"
+)
+
+type CollectionHandler struct {
+	db *database.VectorDB
+}
+
+func NewCollectionHandler(db *database.VectorDB) *CollectionHandler {
+	return &CollectionHandler{db: db}
+}
+
+// ListCollectionSettings handles GET /admin/collections
+func (h *CollectionHandler) ListCollectionSettings(c *gin.Context) {
+	settings, err := h.db.ListCollectionSettings(c.Request.Context(), tenantFor(c).ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collections": settings,
+		"count":       len(settings),
+	})
+}
+
+// SaveCollectionSettings handles PUT /admin/collections/:collection
+func (h *CollectionHandler) SaveCollectionSettings(c *gin.Context) {
+	var req struct {
+		RetrievalMode string `json:"retrieval_mode" binding:"omitempty,oneof=vector hyde"`
+		HyDEAverage   bool   `json:"hyde_average"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection := c.Param("collection")
+	if !authorizeCollection(c, collection) {
+		return
+	}
+
+	settings := database.CollectionSettings{
+		Collection:    collection,
+		RetrievalMode: req.RetrievalMode,
+		HyDEAverage:   req.HyDEAverage,
+	}
+	if err := h.db.SaveCollectionSettings(c.Request.Context(), tenantFor(c).ID, settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"example.com/hello/auth"
+	"example.com/hello/feedback"
+	"github.com/gin-gonic/gin"
+)
+
+type FeedbackHandler struct {
+	store *feedback.Store
+}
+
+func NewFeedbackHandler(store *feedback.Store) *FeedbackHandler {
+	return &FeedbackHandler{store: store}
+}
+
+// answerID accepts both answer IDs and OpenAI completion IDs (chatcmpl-<answer_id>)
+func answerID(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("id"), "chatcmpl-")
+}
+
+// SubmitFeedback handles POST /api/v1/answers/:id/feedback
+func (h *FeedbackHandler) SubmitFeedback(c *gin.Context) {
+	var req struct {
+		Rating       string `json:"rating" binding:"required,oneof=up down"`
+		Comment      string `json:"comment" binding:"max=4000"`
+		WrongSources []int  `json:"wrong_sources"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	trace, err := h.store.GetTrace(ctx, tenantFor(c).ID, answerID(c))
+	if errors.Is(err, feedback.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 잘못된 근거로 지적할 수 있는 문서는 답변에 사용된 문서뿐
+	sources := trace.SourceIDs()
+	for _, id := range req.WrongSources {
+		if !slices.Contains(sources, id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("document %d is not a source of this answer", id)})
+			return
+		}
+	}
+
+	f := feedback.Feedback{
+		AnswerID:     trace.AnswerID,
+		Rating:       req.Rating,
+		Comment:      req.Comment,
+		WrongSources: req.WrongSources,
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		f.Principal = p.ID
+	}
+	saved, err := h.store.Submit(ctx, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, saved)
+}
+
+// GetTrace handles GET /admin/answers/:id/trace
+func (h *FeedbackHandler) GetTrace(c *gin.Context) {
+	trace, err := h.store.GetTrace(c.Request.Context(), tenantFor(c).ID, answerID(c))
+	if errors.Is(err, feedback.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trace)
+}
+
+type feedbackQuery struct {
+	Rating     string `form:"rating" binding:"omitempty,oneof=up down"`
+	Collection string `form:"collection"`
+	Days       int    `form:"days" binding:"omitempty,min=1,max=365"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=10000"`
+}
+
+func (q feedbackQuery) filter() feedback.ListFilter {
+	filter := feedback.ListFilter{Rating: q.Rating, Collection: q.Collection, Limit: q.Limit}
+	if q.Days > 0 {
+		filter.Since = time.Now().AddDate(0, 0, -q.Days)
+	}
+	return filter
+}
+
+// ListFeedback handles GET /admin/feedback
+func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
+	var q feedbackQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.store.List(c.Request.Context(), tenantFor(c).ID, q.filter())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"feedback": entries,
+		"count":    len(entries),
+	})
+}
+
+// ExportFeedback handles GET /admin/feedback/export.
+// eval dataset 형식(JSONL)으로 내보내 `eval run -dataset`에 바로 사용할 수 있다.
+func (h *FeedbackHandler) ExportFeedback(c *gin.Context) {
+	var q feedbackQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if q.Limit == 0 {
+		q.Limit = 10000
+	}
+
+	entries, err := h.store.List(c.Request.Context(), tenantFor(c).ID, q.filter())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="feedback.jsonl"`)
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	for _, ex := range feedback.ToExamples(entries) {
+		if err := enc.Encode(ex); err != nil {
+			return
+		}
+	}
+}
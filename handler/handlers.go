@@ -1,79 +1,137 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
+	"example.com/hello/auth"
 	"example.com/hello/chat"
 	"example.com/hello/embedding"
-	"example.com/hello/reranker"
+	"example.com/hello/logging"
+	"example.com/hello/middleware"
+	"example.com/hello/rag"
+	"example.com/hello/ratelimit"
+	"example.com/hello/tenant"
 	database "example.com/hello/vector"
 	"github.com/gin-gonic/gin"
 )
 
 type DocumentHandler struct {
-	db              *database.VectorDB
-	embService      *embedding.Service
-	rerankerService *reranker.Service
-	llmChatService  *chat.Service
+	db         *database.VectorDB
+	embService *embedding.Service
+	pipeline   *rag.Pipeline
+	quota      *ratelimit.QuotaTracker
 }
 
-func NewDocumentHandler(db *database.VectorDB, embService *embedding.Service, rerankerService *reranker.Service, llmChatService *chat.Service) *DocumentHandler {
+func NewDocumentHandler(db *database.VectorDB, embService *embedding.Service, pipeline *rag.Pipeline, quota *ratelimit.QuotaTracker) *DocumentHandler {
 	return &DocumentHandler{
-		db:              db,
-		embService:      embService,
-		rerankerService: rerankerService,
-		llmChatService:  llmChatService,
+		db:         db,
+		embService: embService,
+		pipeline:   pipeline,
+		quota:      quota,
 	}
 }
 
 // RagChatting
 func (h *DocumentHandler) RagChatting(c *gin.Context) {
 	var req struct {
-		Content string `json:"content" binding:"required"`
+		Content    string `json:"content" binding:"required"`
+		Collection string `json:"collection"`
+		// "name" 또는 "name@version". 비어 있으면 collection 바인딩 또는 기본 템플릿
+		PromptTemplate string `json:"prompt_template"`
+		RerankTemplate string `json:"rerank_template"`
+		Language       string `json:"language"`
+		// temperature, top_p, max_tokens, stop, seed
+		Options chat.GenerateOptions `json:"options"`
+		// 추가 검색 query 수 (없으면 QUERY_REWRITES 설정)
+		QueryRewrites *int `json:"query_rewrites" binding:"omitempty,min=0,max=5"`
+		// vector 또는 hyde (없으면 collection 설정)
+		RetrievalMode string `json:"retrieval_mode" binding:"omitempty,oneof=vector hyde"`
+		HyDEAverage   *bool  `json:"hyde_average"`
+		// MMR 다양화 (없으면 MMR_ENABLED, MMR_LAMBDA 설정)
+		MMR       *bool    `json:"mmr"`
+		MMRLambda *float64 `json:"mmr_lambda" binding:"omitempty,min=0,max=1"`
+		// 단계별 pipeline trace 반환 (admin scope만)
+		Debug bool `json:"debug"`
 		//Embedding []float32 `json:"embedding" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	// chatting request embedding 처리
-	// embedding api로 질의문 vector 데이터로 변환
-	embChatData, err := h.embService.GenerateEmbedding(c.Request.Context(), req.Content)
-	fmt.Println("logging embed data:", embChatData)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !authorizeCollection(c, req.Collection) {
+		return
 	}
-	// vector 데이터로 db 데이터 조회
-	similar, err := h.db.SearchSimilar(c.Request.Context(), embChatData, 3)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if req.Debug && !canDebug(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "debug requires the admin scope"})
+		return
 	}
-	fmt.Println("similar:", similar)
-	// rerank 처리
-	//rerank, err := h.rerankerService.FastRerank(c.Request.Context(), req.Content, similar)
 
-	rerank, err := h.rerankerService.Rerank(c.Request.Context(), req.Content, similar)
+	// 일일 토큰 quota 확인 (tenant 단위)
+	t := tenantFor(c)
+	ok, resetIn, err := h.quota.Allow(c.Request.Context(), t.ID, t.DailyTokenQuota)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	fmt.Println("rerank:", rerank)
-	// llm 처리
-	answer, err := h.llmChatService.Chat(c.Request.Context(), req.Content, rerank)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !ok {
+		middleware.TooManyRequests(c, resetIn, "daily token quota exceeded")
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"answer": answer,
+	// embedding → 검색 → rerank → llm
+	result, err := h.pipeline.Run(c.Request.Context(), rag.Request{
+		Question:       req.Content,
+		Tenant:         t,
+		Collection:     req.Collection,
+		Access:         accessFor(c),
+		PromptTemplate: req.PromptTemplate,
+		RerankTemplate: req.RerankTemplate,
+		Language:       req.Language,
+		Generation:     req.Options,
+		QueryRewrites:  req.QueryRewrites,
+		RetrievalMode:  req.RetrievalMode,
+		HyDEAverage:    req.HyDEAverage,
+		MMR:            req.MMR,
+		MMRLambda:      req.MMRLambda,
+		Debug:          req.Debug,
 	})
+	if result != nil {
+		// 실패한 요청도 이미 사용한 토큰은 quota에 반영
+		addUsage(c, h.quota, t.ID, result.Usage)
+	}
+	if err != nil {
+		// 잘못된 템플릿 이름 등 요청 오류만 400, LLM/DB 등 나머지는 502
+		var reqErr *rag.RequestError
+		if errors.As(err, &reqErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"answer_id": result.ID,
+		"answer":    result.Answer,
+		"usage":     result.Usage,
+		"prompt":    result.Prompt,
+		"sources":   result.Sources,
+	}
+	if result.Debug != nil {
+		response["debug"] = result.Debug
+	}
+	c.JSON(http.StatusOK, response)
 
 }
 
 // InsertDocument handles POST /documents
 func (h *DocumentHandler) InsertAllDocument(c *gin.Context) {
 	var req struct {
-		Content []string `json:"content" binding:"required"`
+		Content       []string `json:"content" binding:"required"`
+		Collection    string   `json:"collection"`
+		AllowedUsers  []string `json:"allowed_users"`
+		AllowedGroups []string `json:"allowed_groups"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -81,19 +139,37 @@ func (h *DocumentHandler) InsertAllDocument(c *gin.Context) {
 		return
 	}
 
-	for _, content := range req.Content {
-		embeddingData, err2 := h.embService.GenerateEmbedding(c.Request.Context(), content)
-		if err2 != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err2.Error()})
-		}
-		_, err := h.db.InsertDocument(c.Request.Context(), content, embeddingData)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+	if !authorizeCollection(c, req.Collection) {
+		return
+	}
+
+	// embedding을 모두 생성한 뒤 한 트랜잭션으로 저장 (중단 시 일부만 저장되지 않도록)
+	embeddings, err := h.embService.GenerateBatchEmbeddings(c.Request.Context(), req.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	docs := make([]database.Document, len(req.Content))
+	for i, content := range req.Content {
+		docs[i] = database.Document{
+			TenantID:      tenantFor(c).ID,
+			Collection:    req.Collection,
+			Content:       content,
+			Embedding:     embeddings[i],
+			AllowedUsers:  req.AllowedUsers,
+			AllowedGroups: req.AllowedGroups,
 		}
 	}
 
+	ids, err := h.db.InsertDocuments(c.Request.Context(), docs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
+		"ids":     ids,
 		"message": "Document inserted successfully",
 	})
 }
@@ -101,7 +177,10 @@ func (h *DocumentHandler) InsertAllDocument(c *gin.Context) {
 // InsertDocument handles POST /documents
 func (h *DocumentHandler) InsertDocument(c *gin.Context) {
 	var req struct {
-		Content string `json:"content" binding:"required"`
+		Content       string   `json:"content" binding:"required"`
+		Collection    string   `json:"collection"`
+		AllowedUsers  []string `json:"allowed_users"`
+		AllowedGroups []string `json:"allowed_groups"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -109,12 +188,24 @@ func (h *DocumentHandler) InsertDocument(c *gin.Context) {
 		return
 	}
 
-	embedding, err2 := h.embService.GenerateEmbedding(c.Request.Context(), req.Content)
+	if !authorizeCollection(c, req.Collection) {
+		return
+	}
+
+	embedding, err2 := h.embService.GenerateDocumentEmbedding(c.Request.Context(), req.Content)
 	if err2 != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err2.Error()})
+		return
 	}
 
-	id, err := h.db.InsertDocument(c.Request.Context(), req.Content, embedding)
+	id, err := h.db.InsertDocument(c.Request.Context(), database.Document{
+		TenantID:      tenantFor(c).ID,
+		Collection:    req.Collection,
+		Content:       req.Content,
+		Embedding:     embedding,
+		AllowedUsers:  req.AllowedUsers,
+		AllowedGroups: req.AllowedGroups,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -138,15 +229,69 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 		return
 	}
 
-	doc, err := h.db.GetDocumentByID(c.Request.Context(), id.ID)
+	doc, err := h.db.GetDocumentByID(c.Request.Context(), tenantFor(c).ID, id.ID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
 	}
 
+	// 접근 권한이 없는 문서는 존재 여부도 노출하지 않는다
+	p, ok := auth.FromContext(c.Request.Context())
+	if (ok && !p.CanAccessCollection(doc.Collection)) || !accessFor(c).CanRead(doc) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, doc)
 }
 
+// authorizeCollection checks that the caller may use collection and responds 403 otherwise
+func authorizeCollection(c *gin.Context, collection string) bool {
+	if collection == "" {
+		collection = database.DefaultCollection
+	}
+	if p, ok := auth.FromContext(c.Request.Context()); ok && !p.CanAccessCollection(collection) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key is not allowed to access collection " + collection})
+		return false
+	}
+	return true
+}
+
+// canDebug reports whether the caller may request a pipeline trace (문서 내용과 프롬프트가 노출되므로 admin만)
+func canDebug(c *gin.Context) bool {
+	p, ok := auth.FromContext(c.Request.Context())
+	return ok && p.IsAdmin()
+}
+
+// tenantFor returns the tenant resolved by middleware.Tenant
+func tenantFor(c *gin.Context) *tenant.Tenant {
+	if t, ok := tenant.FromContext(c.Request.Context()); ok {
+		return t
+	}
+	return &tenant.Tenant{ID: tenant.DefaultID}
+}
+
+// addUsage charges tokens used by a request to the tenant's daily quota
+func addUsage(c *gin.Context, quota *ratelimit.QuotaTracker, tenantID string, usage chat.Usage) {
+	if err := quota.Add(c.Request.Context(), tenantID, usage.Total()); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("failed to record token usage", "tenant", tenantID, "error", err)
+	}
+}
+
+// accessFor returns the document ACL identity of the caller.
+// tenant admin scope도 문서 ACL은 따르고, platform admin(bootstrap key)만 ACL을 무시한다.
+func accessFor(c *gin.Context) database.Access {
+	p, ok := auth.FromContext(c.Request.Context())
+	if !ok {
+		return database.Access{}
+	}
+	return database.Access{
+		User:   p.User,
+		Groups: p.Groups,
+		All:    p.PlatformAdmin,
+	}
+}
+
 //// SearchSimilar handles POST /documents/search
 //func (h *DocumentHandler) SearchSimilar(c *gin.Context) {
 //	var req models.SearchRequest
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"example.com/hello/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Livez handles GET /livez
+// 프로세스가 요청을 처리할 수 있는지만 확인한다 (의존성 검사 없음)
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+// Readyz handles GET /readyz
+// DB, embedding, chat, rerank 의존성 상태를 캐시된 probe 결과로 반환한다 (shutdown이 시작되면 503)
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	results, healthy := h.checker.Check(c.Request.Context())
+
+	status := http.StatusOK
+	overall := health.StatusUp
+	if !healthy {
+		status = http.StatusServiceUnavailable
+		overall = health.StatusDown
+	}
+
+	c.JSON(status, gin.H{
+		"status":       overall,
+		"draining":     h.checker.Draining(),
+		"dependencies": results,
+	})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"example.com/hello/ingest"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportDocuments handles POST /documents/import (multipart: file, content_template, metadata, collection).
+// CSV/JSONL의 row마다 문서 하나를 만들고, 잘못된 row는 errors에 줄 번호와 함께 반환한다.
+func (h *DocumentHandler) ImportDocuments(c *gin.Context) {
+	var req struct {
+		Collection      string `form:"collection"`
+		Format          string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+		ContentTemplate string `form:"content_template" binding:"required"`
+		// metadata는 JSON object: {"metadata key": "field 이름"}
+		Metadata      string   `form:"metadata"`
+		AllowedUsers  []string `form:"allowed_users"`
+		AllowedGroups []string `form:"allowed_groups"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !authorizeCollection(c, req.Collection) {
+		return
+	}
+
+	var metadata map[string]string
+	if req.Metadata != "" {
+		if err := json.Unmarshal([]byte(req.Metadata), &metadata); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "metadata must be a JSON object of field names: " + err.Error()})
+			return
+		}
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = ingest.FormatOf(file.Filename)
+	}
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	report, err := ingest.NewRecordIngester(h.db, h.embService).Ingest(c.Request.Context(), f, ingest.RecordOptions{
+		TenantID:      tenantFor(c).ID,
+		Collection:    req.Collection,
+		Format:        format,
+		Content:       req.ContentTemplate,
+		Metadata:      metadata,
+		Source:        file.Filename,
+		AllowedUsers:  req.AllowedUsers,
+		AllowedGroups: req.AllowedGroups,
+	})
+	if errors.Is(err, ingest.ErrInvalidImport) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		// 중간에 실패해도 이미 저장된 batch는 남으므로 report를 함께 반환
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "report": report})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
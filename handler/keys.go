@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/hello/auth"
+	"example.com/hello/middleware"
+	"example.com/hello/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+type KeyHandler struct {
+	store   *auth.KeyStore
+	tenants *tenant.Store
+}
+
+func NewKeyHandler(store *auth.KeyStore, tenants *tenant.Store) *KeyHandler {
+	return &KeyHandler{store: store, tenants: tenants}
+}
+
+// CreateKey handles POST /admin/keys
+func (h *KeyHandler) CreateKey(c *gin.Context) {
+	var req struct {
+		TenantID    string   `json:"tenant_id"`
+		Name        string   `json:"name" binding:"required"`
+		Scopes      []string `json:"scopes" binding:"required"`
+		Collections []string `json:"collections"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// tenant admin은 자기 tenant의 키만 발급할 수 있다
+	tenantID := tenantFor(c).ID
+	if req.TenantID != "" && req.TenantID != tenantID {
+		if p, ok := auth.FromContext(c.Request.Context()); !ok || !p.PlatformAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "platform admin required to create keys for another tenant"})
+			return
+		}
+		if _, err := h.tenants.Get(c.Request.Context(), req.TenantID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tenantID = req.TenantID
+	}
+
+	plain, key, err := h.store.Create(c.Request.Context(), tenantID, req.Name, req.Scopes, req.Collections)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"key":     plain,
+		"api_key": key,
+	})
+}
+
+// ListKeys handles GET /admin/keys
+func (h *KeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.store.List(c.Request.Context(), keyTenantScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"keys":  keys,
+		"count": len(keys),
+	})
+}
+
+// RotateKey handles POST /admin/keys/:id/rotate
+func (h *KeyHandler) RotateKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key ID"})
+		return
+	}
+
+	plain, key, err := h.store.Rotate(c.Request.Context(), keyTenantScope(c), id)
+	if errors.Is(err, auth.ErrKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"key":     plain,
+		"api_key": key,
+	})
+}
+
+// RevokeKey handles DELETE /admin/keys/:id
+func (h *KeyHandler) RevokeKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key ID"})
+		return
+	}
+
+	err = h.store.Revoke(c.Request.Context(), keyTenantScope(c), id)
+	if errors.Is(err, auth.ErrKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+// keyTenantScope returns the tenant whose keys the caller may manage ("" = all tenants)
+func keyTenantScope(c *gin.Context) string {
+	if p, ok := auth.FromContext(c.Request.Context()); ok && p.PlatformAdmin && c.GetHeader(middleware.TenantHeader) == "" {
+		return ""
+	}
+	return tenantFor(c).ID
+}
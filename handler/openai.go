@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"example.com/hello/auth"
+	"example.com/hello/chat"
+	"example.com/hello/logging"
+	"example.com/hello/middleware"
+	"example.com/hello/rag"
+	"example.com/hello/ratelimit"
+	"example.com/hello/reranker"
+	database "example.com/hello/vector"
+	"github.com/gin-gonic/gin"
+)
+
+// modelPrefix is the model name clients use: "rag" = default collection, "rag:<collection>" = 특정 collection
+const modelPrefix = "rag"
+
+// OpenAIHandler exposes the RAG pipeline as an OpenAI-compatible chat model
+type OpenAIHandler struct {
+	db       *database.VectorDB
+	pipeline *rag.Pipeline
+	quota    *ratelimit.QuotaTracker
+}
+
+func NewOpenAIHandler(db *database.VectorDB, pipeline *rag.Pipeline, quota *ratelimit.QuotaTracker) *OpenAIHandler {
+	return &OpenAIHandler{db: db, pipeline: pipeline, quota: quota}
+}
+
+type openAIMessage struct {
+	Role string `json:"role"`
+	// 문자열 또는 [{"type":"text","text":"..."}] 형식
+	Content json.RawMessage `json:"content"`
+}
+
+type openAIChatRequest struct {
+	Model         string          `json:"model" binding:"required"`
+	Messages      []openAIMessage `json:"messages" binding:"required,min=1"`
+	Stream        bool            `json:"stream"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+	Temperature *float64   `json:"temperature"`
+	TopP        *float64   `json:"top_p"`
+	MaxTokens   int        `json:"max_tokens"`
+	Stop        stopTokens `json:"stop"`
+	Seed        *int       `json:"seed"`
+
+	// 확장 필드 (OpenAI API에는 없음)
+	PromptTemplate string   `json:"prompt_template"`
+	RerankTemplate string   `json:"rerank_template"`
+	Language       string   `json:"language"`
+	QueryRewrites  *int     `json:"query_rewrites" binding:"omitempty,min=0,max=5"`
+	RetrievalMode  string   `json:"retrieval_mode" binding:"omitempty,oneof=vector hyde"`
+	HyDEAverage    *bool    `json:"hyde_average"`
+	MMR            *bool    `json:"mmr"`
+	MMRLambda      *float64 `json:"mmr_lambda" binding:"omitempty,min=0,max=1"`
+	// admin scope만 사용 가능
+	Debug bool `json:"debug"`
+}
+
+// stopTokens accepts "stop" as a string or an array of strings
+type stopTokens []string
+
+func (s *stopTokens) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = nil
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = stopTokens{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("stop must be a string or an array of strings")
+	}
+	*s = list
+	return nil
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type openAIChoice struct {
+	Index        int          `json:"index"`
+	Message      *openAIDelta `json:"message,omitempty"`
+	Delta        *openAIDelta `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+type openAIDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type openAICompletion struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   *openAIUsage   `json:"usage,omitempty"`
+	// Citations는 답변 근거 문서 (확장 필드). [문서 N]은 Citations[N-1]을 가리킨다
+	Citations []reranker.RankedDocument `json:"citations,omitempty"`
+	// Debug는 debug 요청의 pipeline trace (확장 필드, stream이면 마지막 chunk)
+	Debug *rag.Debug `json:"debug,omitempty"`
+}
+
+// ChatCompletions handles POST /v1/chat/completions
+func (h *OpenAIHandler) ChatCompletions(c *gin.Context) {
+	var req openAIChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	collection, ok := collectionFromModel(req.Model)
+	if !ok {
+		openAIError(c, http.StatusNotFound, "model_not_found", fmt.Sprintf("model %q does not exist", req.Model))
+		return
+	}
+	if p, ok := auth.FromContext(c.Request.Context()); ok && !p.CanAccessCollection(collection) {
+		openAIError(c, http.StatusForbidden, "permission_denied", "API key is not allowed to access collection "+collection)
+		return
+	}
+	if req.Debug && !canDebug(c) {
+		openAIError(c, http.StatusForbidden, "permission_denied", "debug requires the admin scope")
+		return
+	}
+
+	// 마지막 user 메시지를 질문으로 사용한다
+	question := ""
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			question = messageText(req.Messages[i].Content)
+			break
+		}
+	}
+	if strings.TrimSpace(question) == "" {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", "messages must contain a user message")
+		return
+	}
+
+	t := tenantFor(c)
+	ok, resetIn, err := h.quota.Allow(c.Request.Context(), t.ID, t.DailyTokenQuota)
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if !ok {
+		middleware.TooManyRequests(c, resetIn, "daily token quota exceeded")
+		return
+	}
+
+	ctx := c.Request.Context()
+	retrieval, err := h.pipeline.Retrieve(ctx, rag.Request{
+		Question:       question,
+		Tenant:         t,
+		Collection:     collection,
+		Access:         accessFor(c),
+		PromptTemplate: req.PromptTemplate,
+		RerankTemplate: req.RerankTemplate,
+		Language:       req.Language,
+		Generation: chat.GenerateOptions{
+			Temperature: req.Temperature,
+			TopP:        req.TopP,
+			MaxTokens:   req.MaxTokens,
+			Stop:        req.Stop,
+			Seed:        req.Seed,
+		},
+		QueryRewrites: req.QueryRewrites,
+		RetrievalMode: req.RetrievalMode,
+		HyDEAverage:   req.HyDEAverage,
+		MMR:           req.MMR,
+		MMRLambda:     req.MMRLambda,
+		Debug:         req.Debug,
+	})
+	if err != nil {
+		var reqErr *rag.RequestError
+		if errors.As(err, &reqErr) {
+			openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		openAIError(c, http.StatusBadGateway, "upstream_error", err.Error())
+		return
+	}
+
+	completion := openAICompletion{
+		ID:        "chatcmpl-" + retrieval.ID,
+		Created:   time.Now().Unix(),
+		Model:     req.Model,
+		Citations: retrieval.Sources,
+	}
+
+	if req.Stream {
+		h.stream(c, retrieval, completion, req.StreamOptions != nil && req.StreamOptions.IncludeUsage)
+		return
+	}
+
+	result, err := h.pipeline.Generate(ctx, retrieval, nil)
+	if result != nil {
+		addUsage(c, h.quota, t.ID, result.Usage)
+	}
+	if err != nil {
+		openAIError(c, http.StatusBadGateway, "upstream_error", err.Error())
+		return
+	}
+
+	stop := "stop"
+	completion.Object = "chat.completion"
+	completion.Choices = []openAIChoice{{
+		Message:      &openAIDelta{Role: "assistant", Content: result.Answer},
+		FinishReason: &stop,
+	}}
+	completion.Usage = toOpenAIUsage(result.Usage)
+	completion.Debug = result.Debug
+	c.JSON(http.StatusOK, completion)
+}
+
+// stream writes the answer as server-sent events (chat.completion.chunk)
+func (h *OpenAIHandler) stream(c *gin.Context, retrieval *rag.Retrieval, completion openAICompletion, includeUsage bool) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	completion.Object = "chat.completion.chunk"
+	send := func(chunk openAICompletion) error {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	// 첫 chunk에 role과 citations를 보낸다
+	first := completion
+	first.Choices = []openAIChoice{{Delta: &openAIDelta{Role: "assistant"}}}
+	if err := send(first); err != nil {
+		return
+	}
+
+	completion.Citations = nil
+	result, err := h.pipeline.Generate(c.Request.Context(), retrieval, func(delta string) error {
+		chunk := completion
+		chunk.Choices = []openAIChoice{{Delta: &openAIDelta{Content: delta}}}
+		return send(chunk)
+	})
+	if result != nil {
+		// client가 끝나기 직전에 연결을 끊어도 그때까지 생성된 토큰은 quota에 반영
+		addUsage(c, h.quota, retrieval.Request.Tenant.ID, result.Usage)
+	}
+	if err != nil {
+		// 이미 200을 보냈으므로 error event로 알린다
+		logging.FromContext(c.Request.Context()).Error("chat completion stream failed", "error", err)
+		data, _ := json.Marshal(gin.H{"error": gin.H{"message": err.Error(), "type": "upstream_error"}})
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		c.Writer.Flush()
+		return
+	}
+
+	stop := "stop"
+	last := completion
+	last.Choices = []openAIChoice{{Delta: &openAIDelta{}, FinishReason: &stop}}
+	last.Debug = result.Debug
+	if err := send(last); err != nil {
+		return
+	}
+	if includeUsage {
+		usage := completion
+		usage.Choices = []openAIChoice{}
+		usage.Usage = toOpenAIUsage(result.Usage)
+		if err := send(usage); err != nil {
+			return
+		}
+	}
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+// ListModels handles GET /v1/models. caller가 접근 가능한 collection마다 모델 하나
+func (h *OpenAIHandler) ListModels(c *gin.Context) {
+	collections, err := h.db.Collections(c.Request.Context(), tenantFor(c).ID)
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	p, authenticated := auth.FromContext(c.Request.Context())
+	models := []gin.H{}
+	seen := map[string]bool{}
+	for _, collection := range append([]string{database.DefaultCollection}, collections...) {
+		if seen[collection] || (authenticated && !p.CanAccessCollection(collection)) {
+			continue
+		}
+		seen[collection] = true
+		models = append(models, gin.H{
+			"id":       modelForCollection(collection),
+			"object":   "model",
+			"created":  0,
+			"owned_by": tenantFor(c).ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   models,
+	})
+}
+
+func collectionFromModel(model string) (string, bool) {
+	if model == modelPrefix {
+		return database.DefaultCollection, true
+	}
+	collection, ok := strings.CutPrefix(model, modelPrefix+":")
+	return collection, ok && collection != ""
+}
+
+func modelForCollection(collection string) string {
+	if collection == database.DefaultCollection {
+		return modelPrefix
+	}
+	return modelPrefix + ":" + collection
+}
+
+// messageText returns the text of a message content (문자열 또는 content part 배열)
+func messageText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range parts {
+		if part.Type == "text" {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
+
+func toOpenAIUsage(usage chat.Usage) *openAIUsage {
+	return &openAIUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.Total(),
+	}
+}
+
+// openAIError writes an error in the OpenAI API format
+func openAIError(c *gin.Context, status int, errType, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"error": gin.H{
+		"message": message,
+		"type":    errType,
+	}})
+}
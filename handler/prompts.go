@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/hello/prompt"
+	database "example.com/hello/vector"
+	"github.com/gin-gonic/gin"
+)
+
+type PromptHandler struct {
+	store *prompt.Store
+}
+
+func NewPromptHandler(store *prompt.Store) *PromptHandler {
+	return &PromptHandler{store: store}
+}
+
+// ListPrompts handles GET /admin/prompts
+func (h *PromptHandler) ListPrompts(c *gin.Context) {
+	templates, err := h.store.List(c.Request.Context(), tenantFor(c).ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// CreatePrompt handles POST /admin/prompts (같은 이름이면 새 버전 생성)
+func (h *PromptHandler) CreatePrompt(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+		Kind string `json:"kind" binding:"required"`
+		Body string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.store.Create(c.Request.Context(), tenantFor(c).ID, req.Name, req.Kind, req.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, t)
+}
+
+// GetPromptVersions handles GET /admin/prompts/:name
+func (h *PromptHandler) GetPromptVersions(c *gin.Context) {
+	versions, err := h.store.Versions(c.Request.Context(), tenantFor(c).ID, c.Param("name"))
+	if errors.Is(err, prompt.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"versions": versions,
+		"count":    len(versions),
+	})
+}
+
+// GetPrompt handles GET /admin/prompts/:name/versions/:version
+func (h *PromptHandler) GetPrompt(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template version"})
+		return
+	}
+
+	t, err := h.store.Get(c.Request.Context(), tenantFor(c).ID, c.Param("name"), version)
+	if errors.Is(err, prompt.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+// DeletePrompt handles DELETE /admin/prompts/:name
+func (h *PromptHandler) DeletePrompt(c *gin.Context) {
+	err := h.store.Delete(c.Request.Context(), tenantFor(c).ID, c.Param("name"))
+	if errors.Is(err, prompt.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Prompt template deleted successfully"})
+}
+
+// ListPromptBindings handles GET /admin/prompt-bindings
+func (h *PromptHandler) ListPromptBindings(c *gin.Context) {
+	bindings, err := h.store.Bindings(c.Request.Context(), tenantFor(c).ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bindings": bindings,
+		"count":    len(bindings),
+	})
+}
+
+// BindPrompt handles PUT /admin/prompt-bindings/:collection (name이 비어 있으면 바인딩 해제)
+func (h *PromptHandler) BindPrompt(c *gin.Context) {
+	var req struct {
+		Kind string `json:"kind" binding:"required,oneof=chat rerank rewrite hyde judge"`
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection := c.Param("collection")
+	if collection == "" {
+		collection = database.DefaultCollection
+	}
+	if !authorizeCollection(c, collection) {
+		return
+	}
+
+	binding := prompt.Binding{Collection: collection, Kind: req.Kind, Name: req.Name}
+	err := h.store.Bind(c.Request.Context(), tenantFor(c).ID, binding)
+	if errors.Is(err, prompt.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, binding)
+}
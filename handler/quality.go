@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"example.com/hello/eval"
+	"github.com/gin-gonic/gin"
+)
+
+type QualityHandler struct {
+	scores *eval.ScoreStore
+}
+
+func NewQualityHandler(scores *eval.ScoreStore) *QualityHandler {
+	return &QualityHandler{scores: scores}
+}
+
+// GetQualityTrend handles GET /admin/quality/trend
+func (h *QualityHandler) GetQualityTrend(c *gin.Context) {
+	var req struct {
+		Days       int    `form:"days" binding:"omitempty,min=1,max=365"`
+		Source     string `form:"source" binding:"omitempty,oneof=live eval"`
+		Collection string `form:"collection"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(req.Days - 1))
+	points, err := h.scores.Trend(c.Request.Context(), tenantFor(c).ID, eval.TrendFilter{
+		Since:      since,
+		Source:     req.Source,
+		Collection: req.Collection,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":  since,
+		"points": points,
+	})
+}
@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/hello/audit"
+	"example.com/hello/auth"
+	"example.com/hello/eval"
+	"example.com/hello/feedback"
+	"example.com/hello/internal/dbtest"
+	"example.com/hello/middleware"
+	"example.com/hello/prompt"
+	"example.com/hello/tenant"
+	database "example.com/hello/vector"
+	"github.com/gin-gonic/gin"
+)
+
+// testAuthenticator maps a bearer credential to a fixed principal
+type testAuthenticator map[string]*auth.Principal
+
+func (a testAuthenticator) Authenticate(_ context.Context, credential string) (*auth.Principal, error) {
+	if p, ok := a[credential]; ok {
+		return p, nil
+	}
+	return nil, auth.ErrInvalidKey
+}
+
+// leakEnv is a router with the production middleware chain and data stored for tenant A
+type leakEnv struct {
+	router  *gin.Engine
+	tenantA string
+	docID   int
+	keyID   int
+	answer  string
+}
+
+const leakPrompt = "leak-test"
+
+// builtinNames are the templates every tenant sees in /admin/prompts
+var builtinNames = []string{prompt.DefaultChat, prompt.DefaultRerank, prompt.DefaultRewrite, prompt.DefaultHyDE, prompt.DefaultJudge}
+
+// newLeakEnv connects to TEST_DATABASE_URL (pgvector가 설치된 Postgres), 없으면 test를 건너뛴다
+func newLeakEnv(t *testing.T) *leakEnv {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db, err := database.New(ctx, dbtest.DSN(t))
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+	pool := db.Pool()
+	tenantStore := tenant.NewStore(pool)
+	keyStore := auth.NewKeyStore(pool, "")
+	promptStore := prompt.NewStore(pool)
+	feedbackStore := feedback.NewStore(pool)
+	auditStore := audit.NewStore(pool)
+	scoreStore := eval.NewScoreStore(pool)
+	dbtest.Migrate(t, db, tenantStore, keyStore, promptStore, feedbackStore, auditStore, scoreStore)
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	env := &leakEnv{tenantA: "test-a-" + suffix, answer: "answer-" + suffix}
+	tenantB := "test-b-" + suffix
+	t.Cleanup(func() {
+		ids := []string{env.tenantA, tenantB}
+		for _, table := range []string{"documents", "collection_settings", "api_keys", "prompt_templates", "prompt_bindings",
+			"answer_traces", "chat_audit", "answer_scores", "tenants"} {
+			column := "tenant_id"
+			if table == "tenants" {
+				column = "id"
+			}
+			pool.Exec(context.Background(), "DELETE FROM "+table+" WHERE "+column+" = ANY($1)", ids)
+		}
+	})
+
+	// tenant A의 데이터
+	for _, id := range []string{env.tenantA, tenantB} {
+		if err := tenantStore.Save(ctx, &tenant.Tenant{ID: id, Name: id}); err != nil {
+			t.Fatalf("Save tenant: %v", err)
+		}
+	}
+	embedding := make([]float32, 1024)
+	for i := range embedding {
+		embedding[i] = 0.1
+	}
+	if env.docID, err = db.InsertDocument(ctx, database.Document{TenantID: env.tenantA, Content: "tenant A secret", Embedding: embedding}); err != nil {
+		t.Fatalf("InsertDocument: %v", err)
+	}
+	_, key, err := keyStore.Create(ctx, env.tenantA, "tenant A key", []string{auth.ScopeRead}, nil)
+	if err != nil {
+		t.Fatalf("Create key: %v", err)
+	}
+	env.keyID = key.ID
+	if _, err := promptStore.Create(ctx, env.tenantA, leakPrompt, prompt.KindChat, "tenant A prompt {{.Question}}"); err != nil {
+		t.Fatalf("Create prompt: %v", err)
+	}
+	if err := promptStore.Bind(ctx, env.tenantA, prompt.Binding{Collection: "leak-test", Kind: prompt.KindChat, Name: leakPrompt}); err != nil {
+		t.Fatalf("Bind prompt: %v", err)
+	}
+	if err := db.SaveCollectionSettings(ctx, env.tenantA, database.CollectionSettings{Collection: "leak-test", RetrievalMode: "hyde"}); err != nil {
+		t.Fatalf("SaveCollectionSettings: %v", err)
+	}
+	if err := feedbackStore.SaveTrace(ctx, feedback.Trace{AnswerID: env.answer, TenantID: env.tenantA, Collection: "default", Question: "q"}); err != nil {
+		t.Fatalf("SaveTrace: %v", err)
+	}
+	if _, err := feedbackStore.Submit(ctx, feedback.Feedback{AnswerID: env.answer, Rating: feedback.RatingUp, Principal: "user-a"}); err != nil {
+		t.Fatalf("Submit feedback: %v", err)
+	}
+	if err := auditStore.Save(ctx, &audit.Record{AnswerID: env.answer, TenantID: env.tenantA, PrincipalID: "user-a",
+		Collection: "default", Question: "tenant A question", Answer: "tenant A answer", Sources: []audit.Source{}}); err != nil {
+		t.Fatalf("Save audit record: %v", err)
+	}
+	if err := scoreStore.Save(ctx, eval.ScoreRecord{TenantID: env.tenantA, Source: eval.SourceLive, Collection: "default"}); err != nil {
+		t.Fatalf("Save scores: %v", err)
+	}
+
+	principals := testAuthenticator{
+		// tenant B의 admin scope 키 (tenant admin)
+		"tenant-b-admin": {ID: "key:b", TenantID: tenantB, Scopes: []string{auth.ScopeAdmin}},
+		"platform-admin": {ID: "admin", TenantID: tenant.DefaultID, Scopes: []string{auth.ScopeAdmin}, PlatformAdmin: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authn := middleware.Authenticate(principals)
+	docHandler := NewDocumentHandler(db, nil, nil, nil)
+	keyHandler := NewKeyHandler(keyStore, tenantStore)
+	promptHandler := NewPromptHandler(promptStore)
+	collectionHandler := NewCollectionHandler(db)
+	feedbackHandler := NewFeedbackHandler(feedbackStore)
+	auditHandler := NewAuditHandler(auditStore)
+	qualityHandler := NewQualityHandler(scoreStore)
+
+	api := router.Group("/api/v1", authn, middleware.Tenant(tenantStore))
+	api.GET("/documents/:id", middleware.RequireScope(auth.ScopeRead), docHandler.GetDocument)
+
+	admin := router.Group("/admin", authn, middleware.Tenant(tenantStore), middleware.RequireScope(auth.ScopeAdmin))
+	admin.GET("/keys", keyHandler.ListKeys)
+	admin.POST("/keys", keyHandler.CreateKey)
+	admin.POST("/keys/:id/rotate", keyHandler.RotateKey)
+	admin.DELETE("/keys/:id", keyHandler.RevokeKey)
+	admin.GET("/prompts", promptHandler.ListPrompts)
+	admin.GET("/prompts/:name", promptHandler.GetPromptVersions)
+	admin.GET("/prompts/:name/versions/:version", promptHandler.GetPrompt)
+	admin.DELETE("/prompts/:name", promptHandler.DeletePrompt)
+	admin.GET("/prompt-bindings", promptHandler.ListPromptBindings)
+	admin.GET("/collections", collectionHandler.ListCollectionSettings)
+	admin.GET("/quality/trend", qualityHandler.GetQualityTrend)
+	admin.GET("/answers/:id/trace", feedbackHandler.GetTrace)
+	admin.GET("/feedback", feedbackHandler.ListFeedback)
+	admin.GET("/feedback/export", feedbackHandler.ExportFeedback)
+	admin.GET("/audit", auditHandler.SearchAudit)
+	admin.GET("/audit/export", auditHandler.ExportAudit)
+
+	env.router = router
+	return env
+}
+
+// do sends a request with credential and optional X-Tenant-ID header
+func (e *leakEnv) do(method, path, credential, tenantHeader, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+credential)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if tenantHeader != "" {
+		req.Header.Set(middleware.TenantHeader, tenantHeader)
+	}
+	w := httptest.NewRecorder()
+	e.router.ServeHTTP(w, req)
+	return w
+}
+
+// count returns the "count" field of a JSON list response
+func count(t *testing.T, w *httptest.ResponseRecorder) int {
+	t.Helper()
+	var resp struct {
+		Count *int `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Count == nil {
+		t.Fatalf("response is not a JSON list (status %d): %s", w.Code, w.Body.String())
+	}
+	return *resp.Count
+}
+
+// TestCrossTenantAccess calls every tenant-scoped endpoint as tenant B for data of tenant A
+func TestCrossTenantAccess(t *testing.T) {
+	env := newLeakEnv(t)
+	const caller = "tenant-b-admin"
+
+	notFound := []struct{ method, path string }{
+		{http.MethodGet, fmt.Sprintf("/api/v1/documents/%d", env.docID)},
+		{http.MethodPost, fmt.Sprintf("/admin/keys/%d/rotate", env.keyID)},
+		{http.MethodDelete, fmt.Sprintf("/admin/keys/%d", env.keyID)},
+		{http.MethodGet, "/admin/prompts/" + leakPrompt},
+		{http.MethodGet, "/admin/prompts/" + leakPrompt + "/versions/1"},
+		{http.MethodDelete, "/admin/prompts/" + leakPrompt},
+		{http.MethodGet, "/admin/answers/" + env.answer + "/trace"},
+	}
+	for _, tc := range notFound {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			if w := env.do(tc.method, tc.path, caller, "", ""); w.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// 목록에는 tenant A의 항목이 없어야 한다 (prompts는 built-in template만)
+	lists := map[string]int{
+		"/admin/keys": 0, "/admin/prompts": len(builtinNames), "/admin/prompt-bindings": 0,
+		"/admin/collections": 0, "/admin/feedback": 0, "/admin/audit": 0,
+	}
+	for path, want := range lists {
+		t.Run("GET "+path, func(t *testing.T) {
+			w := env.do(http.MethodGet, path, caller, "", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+			}
+			if n := count(t, w); n != want || strings.Contains(w.Body.String(), env.tenantA) || strings.Contains(w.Body.String(), leakPrompt) {
+				t.Errorf("tenant B sees items of tenant A (%d items, want %d): %s", n, want, w.Body.String())
+			}
+		})
+	}
+
+	exports := []string{"/admin/feedback/export", "/admin/audit/export?format=jsonl"}
+	for _, path := range exports {
+		t.Run("GET "+path, func(t *testing.T) {
+			w := env.do(http.MethodGet, path, caller, "", "")
+			if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "" {
+				t.Errorf("status = %d, body = %q, want an empty export", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("GET /admin/quality/trend", func(t *testing.T) {
+		w := env.do(http.MethodGet, "/admin/quality/trend", caller, "", "")
+		var resp struct {
+			Points []eval.TrendPoint `json:"points"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		if len(resp.Points) != 0 {
+			t.Errorf("tenant B sees scores of tenant A: %s", w.Body.String())
+		}
+	})
+
+	t.Run("POST /admin/keys for another tenant", func(t *testing.T) {
+		body := `{"tenant_id":"` + env.tenantA + `","name":"stolen","scopes":["read"]}`
+		if w := env.do(http.MethodPost, "/admin/keys", caller, "", body); w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403: %s", w.Code, w.Body.String())
+		}
+	})
+
+	// tenant B의 요청이 tenant A의 데이터를 지우거나 바꾸지 않았는지 확인
+	t.Run("tenant A data is intact", func(t *testing.T) {
+		if w := env.do(http.MethodGet, "/admin/prompts/"+leakPrompt, "platform-admin", env.tenantA, ""); w.Code != http.StatusOK {
+			t.Errorf("prompt of tenant A: status = %d: %s", w.Code, w.Body.String())
+		}
+		w := env.do(http.MethodGet, "/admin/keys", "platform-admin", env.tenantA, "")
+		if w.Code != http.StatusOK || count(t, w) != 1 || strings.Contains(w.Body.String(), `"revoked_at"`) {
+			t.Errorf("key of tenant A was changed: status = %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+// TestTenantHeader checks that only platform admins may switch tenants with X-Tenant-ID
+func TestTenantHeader(t *testing.T) {
+	env := newLeakEnv(t)
+	docPath := fmt.Sprintf("/api/v1/documents/%d", env.docID)
+
+	t.Run("ignored for tenant admins", func(t *testing.T) {
+		if w := env.do(http.MethodGet, docPath, "tenant-b-admin", env.tenantA, ""); w.Code != http.StatusNotFound {
+			t.Errorf("document: status = %d, want 404: %s", w.Code, w.Body.String())
+		}
+		w := env.do(http.MethodGet, "/admin/audit", "tenant-b-admin", env.tenantA, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("audit: status = %d: %s", w.Code, w.Body.String())
+		}
+		if n := count(t, w); n != 0 {
+			t.Errorf("audit: tenant B sees %d records of tenant A", n)
+		}
+	})
+
+	t.Run("honored for platform admins", func(t *testing.T) {
+		if w := env.do(http.MethodGet, docPath, "platform-admin", env.tenantA, ""); w.Code != http.StatusOK {
+			t.Errorf("document: status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		w := env.do(http.MethodGet, "/admin/audit", "platform-admin", env.tenantA, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("audit: status = %d: %s", w.Code, w.Body.String())
+		}
+		if n := count(t, w); n != 1 {
+			t.Errorf("audit: platform admin sees %d records of tenant A, want 1", n)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"example.com/hello/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	store *tenant.Store
+}
+
+func NewTenantHandler(store *tenant.Store) *TenantHandler {
+	return &TenantHandler{store: store}
+}
+
+// ListTenants handles GET /admin/tenants
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.store.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tenants": tenants,
+		"count":   len(tenants),
+	})
+}
+
+// GetTenant handles GET /admin/tenants/:id
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	t, err := h.store.Get(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, tenant.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+// SaveTenant handles PUT /admin/tenants/:id
+func (h *TenantHandler) SaveTenant(c *gin.Context) {
+	var req struct {
+		Name            string `json:"name" binding:"required"`
+		ChatModel       string `json:"chat_model"`
+		RerankerModel   string `json:"reranker_model"`
+		SystemPrompt    string `json:"system_prompt"`
+		DailyTokenQuota int    `json:"daily_token_quota"`
+		// 0이면 전역 AUDIT_RETENTION_DAYS
+		AuditRetentionDays int `json:"audit_retention_days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t := &tenant.Tenant{
+		ID:                 c.Param("id"),
+		Name:               req.Name,
+		ChatModel:          req.ChatModel,
+		RerankerModel:      req.RerankerModel,
+		SystemPrompt:       req.SystemPrompt,
+		DailyTokenQuota:    req.DailyTokenQuota,
+		AuditRetentionDays: req.AuditRetentionDays,
+	}
+	if err := h.store.Save(c.Request.Context(), t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
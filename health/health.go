@@ -0,0 +1,138 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Probe checks a single dependency and returns an error when it is unhealthy
+type Probe func(ctx context.Context) error
+
+// Result represents the last observed state of a dependency
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	LatencyMS float64   `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"`
+}
+
+type entry struct {
+	mu     sync.Mutex
+	name   string
+	probe  Probe
+	last   Result
+	hasRun bool
+}
+
+// Checker runs dependency probes and caches their results.
+// 한 probe는 ttl 안에 최대 한 번만 실행되므로 /readyz 호출이 몰려도 upstream에 부하를 주지 않는다.
+type Checker struct {
+	ttl      time.Duration
+	timeout  time.Duration
+	entries  []*entry
+	draining atomic.Bool
+}
+
+// NewChecker creates a new dependency checker
+func NewChecker(ttl, timeout time.Duration) *Checker {
+	return &Checker{
+		ttl:     ttl,
+		timeout: timeout,
+	}
+}
+
+// Register adds a named probe to the checker
+func (c *Checker) Register(name string, probe Probe) {
+	c.entries = append(c.entries, &entry{name: name, probe: probe})
+}
+
+// StartDraining marks the process as shutting down; 이후 Check는 의존성과 관계없이 not ready를 반환한다
+func (c *Checker) StartDraining() {
+	c.draining.Store(true)
+}
+
+// Draining reports whether shutdown has begun
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Check runs every registered probe (or returns cached results) concurrently
+func (c *Checker) Check(ctx context.Context) ([]Result, bool) {
+	results := make([]Result, len(c.entries))
+
+	var wg sync.WaitGroup
+	for i, e := range c.entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = c.run(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	healthy := !c.Draining()
+	for _, r := range results {
+		if r.Status != StatusUp {
+			healthy = false
+		}
+	}
+	return results, healthy
+}
+
+func (c *Checker) run(ctx context.Context, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// 캐시된 결과가 유효하면 그대로 반환
+	if e.hasRun && time.Since(e.last.CheckedAt) < c.ttl {
+		cached := e.last
+		cached.Cached = true
+		return cached
+	}
+
+	// 결과는 다른 요청과 공유되므로 호출한 요청이 끊겨도 probe는 timeout까지 실행한다
+	probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := e.probe(probeCtx)
+	result := Result{
+		Name:      e.name,
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	e.last = result
+	e.hasRun = true
+	return result
+}
+
+// Run refreshes every probe in the background until ctx is canceled,
+// so /readyz usually answers from a warm cache
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+
+	for {
+		c.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// tagsResponse represents the response from the Ollama /api/tags endpoint
+type tagsResponse struct {
+	Models []struct {
+		Name  string `json:"name"`
+		Model string `json:"model"`
+	} `json:"models"`
+}
+
+// OllamaModelProbe checks that the Ollama host behind apiURL is reachable
+// and that the configured model has been pulled
+func OllamaModelProbe(client *http.Client, apiURL, model string) Probe {
+	return func(ctx context.Context) error {
+		tagsURL, err := ollamaTagsURL(apiURL)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", tagsURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("ngrok-skip-browser-warning", "true")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var tags tagsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		for _, m := range tags.Models {
+			if modelMatches(m.Name, model) || modelMatches(m.Model, model) {
+				return nil
+			}
+		}
+		return fmt.Errorf("model %q is not pulled on %s", model, tagsURL)
+	}
+}
+
+// ollamaTagsURL derives the /api/tags URL from a configured Ollama API URL
+// (예: https://host/api/embeddings -> https://host/api/tags)
+func ollamaTagsURL(apiURL string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid API URL %q", apiURL)
+	}
+
+	prefix := u.Path
+	if i := strings.Index(prefix, "/api/"); i >= 0 {
+		prefix = prefix[:i]
+	} else {
+		prefix = ""
+	}
+
+	return u.Scheme + "://" + u.Host + prefix + "/api/tags", nil
+}
+
+// modelMatches compares model names, treating a missing tag as ":latest"
+func modelMatches(pulled, configured string) bool {
+	if pulled == "" {
+		return false
+	}
+	if !strings.Contains(configured, ":") {
+		configured += ":latest"
+	}
+	if !strings.Contains(pulled, ":") {
+		pulled += ":latest"
+	}
+	return pulled == configured
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// modelsResponse represents the response from the OpenAI /v1/models endpoint
+type modelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// OpenAIModelProbe checks that an OpenAI-compatible server is reachable and serves model
+func OpenAIModelProbe(client *http.Client, apiURL, apiKey, model string) Probe {
+	// 예: http://host/v1/chat/completions -> http://host/v1/models
+	modelsURL := strings.TrimSuffix(apiURL, "/chat/completions") + "/models"
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, "GET", modelsURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var models modelsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&models); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		for _, m := range models.Data {
+			if m.ID == model {
+				return nil
+			}
+		}
+		return fmt.Errorf("model %q is not served by %s", model, modelsURL)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"example.com/hello/config"
+	"example.com/hello/embedding"
+	"example.com/hello/ingest"
+	"example.com/hello/logging"
+	"example.com/hello/tenant"
+	"example.com/hello/vector"
+)
+
+const importUsage = `usage:
+  hello import -file faq.csv -content 'Q: {{.question}}\nA: {{.answer}}' [-meta category,sku=product_id] [-format csv|jsonl] [-collection name] [-tenant id] [-dry-run] [-- config flags]`
+
+// runImport stores every row of a CSV or JSONL file as a document
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "CSV (with header) or JSONL file")
+	format := flags.String("format", "", "csv or jsonl (default: from the file extension)")
+	content := flags.String("content", "", `text/template of the document content (\n and \t are unescaped)`)
+	contentFile := flags.String("content-file", "", "read the content template from a file")
+	meta := flags.String("meta", "", "comma separated metadata fields (key=field or field)")
+	tenantID := flags.String("tenant", tenant.DefaultID, "tenant the documents belong to")
+	collection := flags.String("collection", vector.DefaultCollection, "collection the documents are stored in")
+	batchSize := flags.Int("batch-size", ingest.DefaultBatchSize, "rows embedded and inserted together")
+	dryRun := flags.Bool("dry-run", false, "check the rows without writing to the database")
+	jsonOut := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" || (*content == "" && *contentFile == "") {
+		return errors.New(importUsage)
+	}
+
+	tmpl := strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(*content)
+	if *contentFile != "" {
+		data, err := os.ReadFile(*contentFile)
+		if err != nil {
+			return fmt.Errorf("failed to read content template: %w", err)
+		}
+		tmpl = string(data)
+	}
+	if *format == "" {
+		*format = ingest.FormatOf(*file)
+	}
+	metadata := map[string]string{}
+	for _, item := range splitList(*meta) {
+		key, field, ok := strings.Cut(item, "=")
+		if !ok {
+			field = key
+		}
+		metadata[key] = field
+	}
+
+	// "--" 뒤의 인자는 서버와 같은 설정 flag
+	cfg, err := config.Load(flags.Args())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogDebug); err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *file, err)
+	}
+	defer f.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	db, err := vector.New(connectCtx, cfg.GetDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(connectCtx); err != nil {
+		return err
+	}
+	if err := checkTenant(connectCtx, db, *tenantID); err != nil {
+		return err
+	}
+
+	ingester := ingest.NewRecordIngester(db, embedding.NewService(cfg.EmbeddingAPIURL, cfg.EmbeddingModel))
+	report, err := ingester.Ingest(ctx, f, ingest.RecordOptions{
+		TenantID:   *tenantID,
+		Collection: *collection,
+		Format:     *format,
+		Content:    tmpl,
+		Metadata:   metadata,
+		Source:     filepath.Base(*file),
+		BatchSize:  *batchSize,
+		DryRun:     *dryRun,
+	})
+	// 중간에 실패해도 그때까지의 결과는 출력
+	if report != nil {
+		if *jsonOut {
+			if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("imported %s: %d rows, %d inserted, %d failed\n", *file, report.Rows, report.Inserted, report.Failed)
+			for _, e := range report.Errors {
+				fmt.Printf("  ! line %d: %s\n", e.Line, e.Error)
+			}
+			if len(report.Errors) < report.Failed {
+				fmt.Printf("  ... %d more errors\n", report.Failed-len(report.Errors))
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d rows failed", report.Failed)
+	}
+	return nil
+}
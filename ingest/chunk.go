@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Default chunk sizes in characters (bge-m3 context 안에 넉넉히 들어가는 크기)
+const (
+	DefaultChunkSize    = 1000
+	DefaultChunkOverlap = 150
+)
+
+// Chunk splits text into chunks of at most size characters, preferring paragraph and line boundaries.
+// overlap만큼 앞 chunk의 끝부분을 다음 chunk 앞에 붙여 문맥이 끊기지 않게 한다.
+func Chunk(text string, size, overlap int) []string {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var pieces []string
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		pieces = append(pieces, split(para, size-overlap)...)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+	for _, piece := range pieces {
+		if current.Len() > 0 && runeLen(current.String())+2+runeLen(piece) > size {
+			prev := current.String()
+			flush()
+			if tail := tail(prev, overlap); tail != "" && runeLen(tail)+2+runeLen(piece) <= size {
+				current.WriteString(tail)
+			}
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(piece)
+	}
+	flush()
+	return chunks
+}
+
+// split breaks a paragraph longer than size at line breaks, then at spaces, then anywhere
+func split(para string, size int) []string {
+	if runeLen(para) <= size {
+		return []string{para}
+	}
+	var out []string
+	var current []rune
+	for _, line := range strings.Split(para, "\n") {
+		for _, r := range []rune(line + "\n") {
+			current = append(current, r)
+			if len(current) < size {
+				continue
+			}
+			// 마지막 공백에서 자른다 (없으면 그대로)
+			cut := len(current)
+			for i := len(current) - 1; i > size/2; i-- {
+				if unicode.IsSpace(current[i]) {
+					cut = i + 1
+					break
+				}
+			}
+			out = append(out, strings.TrimSpace(string(current[:cut])))
+			current = append([]rune(nil), current[cut:]...)
+		}
+	}
+	if s := strings.TrimSpace(string(current)); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// tail returns the last n characters of s, starting at a word boundary
+func tail(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	runes = runes[len(runes)-n:]
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			return strings.TrimSpace(string(runes[i:]))
+		}
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
@@ -0,0 +1,284 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Crawl defaults
+const (
+	DefaultMaxDepth  = 2
+	DefaultMaxPages  = 100
+	DefaultUserAgent = "hello-crawler/1.0"
+	// maxPageSize는 HTML 한 페이지의 최대 크기
+	maxPageSize = 5 << 20
+)
+
+// CrawlOptions selects which pages are fetched
+type CrawlOptions struct {
+	// Seeds는 시작 URL. seed와 같은 host(port 포함)의 링크만 따라간다
+	Seeds []string
+	// MaxDepth는 seed에서 따라갈 링크 수 (0 = seed만)
+	MaxDepth int
+	// MaxPages가 0이면 DefaultMaxPages
+	MaxPages int
+	// Delay는 요청 사이 간격 (robots.txt의 Crawl-delay가 더 길면 그 값)
+	Delay        time.Duration
+	IgnoreRobots bool
+}
+
+// Page is the readable content of one crawled page
+type Page struct {
+	// URL은 canonical URL (<link rel="canonical">이 같은 host면 그 값, 아니면 redirect 후 URL)
+	URL string
+	// Fetched는 queue에서 꺼내 요청한 URL (404/410이 되면 CrawlReport.Gone에 이 값이 기록된다)
+	Fetched string
+	Title   string
+	Text    string
+	Depth   int
+
+	noindex bool
+}
+
+// CrawlReport lists what one crawl fetched and skipped
+type CrawlReport struct {
+	Pages   int          `json:"pages"`
+	Skipped []SkippedURL `json:"skipped,omitempty"`
+	// Gone은 404/410을 반환한 URL (저장된 문서를 지울 대상)
+	Gone   []string    `json:"gone,omitempty"`
+	Errors []FileError `json:"errors,omitempty"`
+}
+
+// SkippedURL is a page that was not fetched or not indexed
+type SkippedURL struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// Crawler fetches HTML pages breadth-first within the seed hosts.
+// DB와 embedding에 의존하지 않으므로 httptest server로 그대로 검증할 수 있다.
+type Crawler struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewCrawler creates a new crawler. client가 nil이면 timeout 30초의 기본 client를 사용한다.
+func NewCrawler(client *http.Client, userAgent string) *Crawler {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &Crawler{client: client, userAgent: userAgent}
+}
+
+type queued struct {
+	url   string
+	depth int
+}
+
+// Crawl fetches pages starting from the seeds and calls visit for every indexable page.
+// visit의 실패는 report에 기록하고 crawl은 계속한다.
+func (c *Crawler) Crawl(ctx context.Context, opts CrawlOptions, visit func(context.Context, *Page) error) (*CrawlReport, error) {
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = DefaultMaxPages
+	}
+
+	hosts := map[string]bool{}
+	var queue []queued
+	for _, seed := range opts.Seeds {
+		u, err := url.Parse(seed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed URL %q: %w", seed, err)
+		}
+		normalized := normalizeURL(u)
+		if normalized == "" {
+			return nil, fmt.Errorf("invalid seed URL %q: must be an absolute http(s) URL", seed)
+		}
+		u, _ = url.Parse(normalized)
+		hosts[u.Host] = true
+		queue = append(queue, queued{url: normalized})
+	}
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("no seed URLs")
+	}
+
+	report := &CrawlReport{}
+	seen := map[string]bool{}
+	for _, q := range queue {
+		seen[q.url] = true
+	}
+	robotsCache := map[string]*robots{}
+	var last time.Time
+
+	for len(queue) > 0 && report.Pages < opts.MaxPages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item := queue[0]
+		queue = queue[1:]
+		u, _ := url.Parse(item.url)
+
+		rules := allowAll
+		if !opts.IgnoreRobots {
+			var ok bool
+			if rules, ok = robotsCache[u.Host]; !ok {
+				rules = c.robots(ctx, u)
+				robotsCache[u.Host] = rules
+			}
+			if !rules.allowed(u.RequestURI()) {
+				report.Skipped = append(report.Skipped, SkippedURL{URL: item.url, Reason: "disallowed by robots.txt"})
+				continue
+			}
+		}
+
+		// 같은 서버에 연달아 요청하지 않도록 간격 유지
+		if wait := time.Until(last.Add(max(opts.Delay, rules.delay))); !last.IsZero() && wait > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		last = time.Now()
+
+		page, links, err := c.fetch(ctx, item.url, report)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.Errors = append(report.Errors, FileError{Path: item.url, Error: err.Error()})
+			continue
+		}
+		if page == nil {
+			continue
+		}
+
+		// redirect나 canonical로 host를 벗어나면 저장하지 않는다
+		final, _ := url.Parse(page.URL)
+		if !hosts[final.Host] {
+			report.Skipped = append(report.Skipped, SkippedURL{URL: item.url, Reason: "redirected to " + final.Host})
+			continue
+		}
+		if page.URL != item.url && seen[page.URL] {
+			continue
+		}
+		seen[page.URL] = true
+		page.Fetched = item.url
+		page.Depth = item.depth
+		report.Pages++
+
+		switch {
+		case page.noindex:
+			report.Skipped = append(report.Skipped, SkippedURL{URL: page.URL, Reason: "noindex"})
+		case page.Text == "":
+			report.Skipped = append(report.Skipped, SkippedURL{URL: page.URL, Reason: "no text"})
+		default:
+			if err := visit(ctx, page); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				report.Errors = append(report.Errors, FileError{Path: page.URL, Error: err.Error()})
+			}
+		}
+
+		if item.depth >= opts.MaxDepth {
+			continue
+		}
+		for _, link := range links {
+			lu, err := url.Parse(link)
+			if err != nil || !hosts[lu.Host] || seen[link] {
+				continue
+			}
+			seen[link] = true
+			queue = append(queue, queued{url: link, depth: item.depth + 1})
+		}
+	}
+	return report, nil
+}
+
+// fetch downloads and parses one page. HTML이 아니거나 없는 페이지는 nil page와 report 항목을 남긴다.
+func (c *Crawler) fetch(ctx context.Context, target string, report *CrawlReport) (*Page, []string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		report.Gone = append(report.Gone, target)
+		return nil, nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		report.Skipped = append(report.Skipped, SkippedURL{URL: target, Reason: "content type " + mediaType})
+		return nil, nil, nil
+	}
+
+	final := resp.Request.URL
+	parsed, err := parseHTML(io.LimitReader(resp.Body, maxPageSize), final)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var links []string
+	if !parsed.nofollow && !strings.Contains(strings.ToLower(resp.Header.Get("X-Robots-Tag")), "nofollow") {
+		links = parsed.links
+	}
+	if parsed.noindex || strings.Contains(strings.ToLower(resp.Header.Get("X-Robots-Tag")), "noindex") {
+		// noindex여도 링크는 따라간다 (목차 페이지 등)
+		return &Page{URL: normalizeURL(final), noindex: true}, links, nil
+	}
+
+	page := &Page{URL: normalizeURL(final), Title: parsed.title, Text: parsed.text}
+	if parsed.canonical != "" {
+		if cu, err := url.Parse(parsed.canonical); err == nil && cu.Host == final.Host {
+			page.URL = parsed.canonical
+		}
+	}
+	return page, links, nil
+}
+
+// robots fetches the robots.txt of u's host. 없으면 전부 허용, 서버 오류면 전부 금지 (RFC 9309)
+func (c *Crawler) robots(ctx context.Context, u *url.URL) *robots {
+	robotsURL := u.Scheme + "://" + u.Host + "/robots.txt"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return disallowAll
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return disallowAll
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll
+	case resp.StatusCode >= 400:
+		return allowAll
+	case resp.StatusCode != http.StatusOK:
+		return allowAll
+	}
+	agent, _, _ := strings.Cut(c.userAgent, "/")
+	return parseRobots(resp.Body, agent)
+}
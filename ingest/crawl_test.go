@@ -0,0 +1,235 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// site serves HTML pages by path and records every request
+type site struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+	times    map[string]time.Time
+}
+
+func newSite(t *testing.T, robotsTxt string, pages map[string]string) *site {
+	t.Helper()
+	s := &site{times: map[string]time.Time{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.RequestURI())
+		s.times[r.URL.RequestURI()] = time.Now()
+		s.mu.Unlock()
+
+		if r.URL.Path == "/robots.txt" {
+			if robotsTxt == "" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, robotsTxt)
+			return
+		}
+		body, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *site) requested(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Contains(s.requests, path)
+}
+
+func htmlDoc(title, body string) string {
+	return "<html><head><title>" + title + "</title></head><body>" + body + "</body></html>"
+}
+
+// crawl runs the crawler and returns the visited pages keyed by URL
+func crawl(t *testing.T, opts CrawlOptions) (map[string]*Page, *CrawlReport) {
+	t.Helper()
+	pages := map[string]*Page{}
+	report, err := NewCrawler(nil, "").Crawl(context.Background(), opts, func(_ context.Context, p *Page) error {
+		pages[p.URL] = p
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Crawl: %v", err)
+	}
+	return pages, report
+}
+
+func TestCrawlSameHostAndDepth(t *testing.T) {
+	other := newSite(t, "", map[string]string{
+		"/": htmlDoc("Other", "<p>other host</p>"),
+	})
+	s := newSite(t, "", map[string]string{
+		"/":       htmlDoc("Home", `<p>home</p><a href="/a">a</a> <a href="/b#top">b</a> <a href="`+other.URL+`/">other</a>`),
+		"/a":      htmlDoc("A", `<p>page a</p><a href="/a/deep">deep</a>`),
+		"/b":      htmlDoc("B", `<p>page b</p>`),
+		"/a/deep": htmlDoc("Deep", `<p>too deep</p>`),
+	})
+
+	pages, report := crawl(t, CrawlOptions{Seeds: []string{s.URL + "/"}, MaxDepth: 1})
+
+	for path, depth := range map[string]int{"/": 0, "/a": 1, "/b": 1} {
+		p, ok := pages[s.URL+path]
+		if !ok {
+			t.Errorf("page %s was not visited", path)
+			continue
+		}
+		if p.Depth != depth {
+			t.Errorf("page %s depth = %d, want %d", path, p.Depth, depth)
+		}
+	}
+	if _, ok := pages[s.URL+"/a/deep"]; ok || s.requested("/a/deep") {
+		t.Errorf("page beyond MaxDepth was fetched")
+	}
+	if other.requested("/") {
+		t.Errorf("link to another host was followed")
+	}
+	if report.Pages != 3 {
+		t.Errorf("report.Pages = %d, want 3", report.Pages)
+	}
+}
+
+func TestCrawlMaxPages(t *testing.T) {
+	s := newSite(t, "", map[string]string{
+		"/":  htmlDoc("Home", `<p>home</p><a href="/1">1</a><a href="/2">2</a><a href="/3">3</a>`),
+		"/1": htmlDoc("1", `<p>one</p>`),
+		"/2": htmlDoc("2", `<p>two</p>`),
+		"/3": htmlDoc("3", `<p>three</p>`),
+	})
+
+	pages, report := crawl(t, CrawlOptions{Seeds: []string{s.URL}, MaxDepth: 2, MaxPages: 2})
+
+	if report.Pages != 2 || len(pages) != 2 {
+		t.Fatalf("crawled %d pages (report %d), want 2", len(pages), report.Pages)
+	}
+	if s.requested("/2") || s.requested("/3") {
+		t.Errorf("pages after MaxPages were fetched")
+	}
+}
+
+func TestCrawlRobots(t *testing.T) {
+	s := newSite(t, "User-agent: *\nDisallow: /private\nCrawl-delay: 0.2\n", map[string]string{
+		"/":          htmlDoc("Home", `<p>home</p><a href="/public">public</a><a href="/private/x">private</a>`),
+		"/public":    htmlDoc("Public", `<p>public</p>`),
+		"/private/x": htmlDoc("Private", `<p>private</p>`),
+	})
+
+	pages, report := crawl(t, CrawlOptions{Seeds: []string{s.URL}, MaxDepth: 1})
+
+	if s.requested("/private/x") {
+		t.Errorf("page disallowed by robots.txt was fetched")
+	}
+	if _, ok := pages[s.URL+"/public"]; !ok {
+		t.Errorf("allowed page was not visited")
+	}
+	if !slices.Contains(report.Skipped, SkippedURL{URL: s.URL + "/private/x", Reason: "disallowed by robots.txt"}) {
+		t.Errorf("report.Skipped = %v, want the disallowed page", report.Skipped)
+	}
+
+	s.mu.Lock()
+	gap := s.times["/public"].Sub(s.times["/"])
+	s.mu.Unlock()
+	if gap < 200*time.Millisecond {
+		t.Errorf("requests were %v apart, want at least the Crawl-delay of 200ms", gap)
+	}
+}
+
+func TestCrawlIgnoreRobots(t *testing.T) {
+	s := newSite(t, "User-agent: *\nDisallow: /\n", map[string]string{
+		"/": htmlDoc("Home", `<p>home</p>`),
+	})
+
+	pages, _ := crawl(t, CrawlOptions{Seeds: []string{s.URL}, IgnoreRobots: true})
+
+	if len(pages) != 1 || s.requested("/robots.txt") {
+		t.Errorf("IgnoreRobots: visited %d pages, robots.txt requested = %v", len(pages), s.requested("/robots.txt"))
+	}
+}
+
+func TestCrawlStripsBoilerplate(t *testing.T) {
+	s := newSite(t, "", map[string]string{
+		"/": htmlDoc("Guide", `
+			<nav><a href="/">Home</a> menu item</nav>
+			<header>site header</header>
+			<div role="navigation">breadcrumbs</div>
+			<main><h1>Install</h1><p>Run the installer.</p><ul><li>step one</li><li>step two</li></ul></main>
+			<aside>related links</aside>
+			<footer>copyright footer</footer>
+			<script>var tracking = 1;</script>`),
+	})
+
+	pages, _ := crawl(t, CrawlOptions{Seeds: []string{s.URL}})
+
+	p, ok := pages[s.URL+"/"]
+	if !ok {
+		t.Fatalf("page was not visited")
+	}
+	if p.Title != "Guide" {
+		t.Errorf("Title = %q, want Guide", p.Title)
+	}
+	want := "# Install\n\nRun the installer.\n\n- step one\n- step two"
+	if p.Text != want {
+		t.Errorf("Text = %q, want %q", p.Text, want)
+	}
+}
+
+func TestCrawlCanonicalURL(t *testing.T) {
+	other := newSite(t, "", nil)
+	s := newSite(t, "", map[string]string{
+		"/":            htmlDoc("Home", `<p>home</p><a href="/doc?ref=nav">doc</a><a href="/elsewhere">elsewhere</a>`),
+		"/doc?ref=nav": `<html><head><link rel="canonical" href="/doc"><title>Doc</title></head><body><p>doc</p></body></html>`,
+		"/elsewhere":   `<html><head><link rel="canonical" href="` + other.URL + `/x"></head><body><p>elsewhere</p></body></html>`,
+	})
+
+	pages, _ := crawl(t, CrawlOptions{Seeds: []string{s.URL}, MaxDepth: 1})
+
+	doc, ok := pages[s.URL+"/doc"]
+	if !ok {
+		t.Fatalf("page was not stored under its canonical URL: %v", keys(pages))
+	}
+	if doc.Fetched != s.URL+"/doc?ref=nav" {
+		t.Errorf("Fetched = %q, want the requested URL", doc.Fetched)
+	}
+	// 다른 host를 가리키는 canonical은 무시
+	if _, ok := pages[s.URL+"/elsewhere"]; !ok {
+		t.Errorf("cross-host canonical was not ignored: %v", keys(pages))
+	}
+}
+
+func TestCrawlGone(t *testing.T) {
+	s := newSite(t, "", map[string]string{
+		"/": htmlDoc("Home", `<p>home</p><a href="/removed">removed</a>`),
+	})
+
+	_, report := crawl(t, CrawlOptions{Seeds: []string{s.URL}, MaxDepth: 1})
+
+	if !slices.Equal(report.Gone, []string{s.URL + "/removed"}) {
+		t.Errorf("report.Gone = %v, want the 404 page", report.Gone)
+	}
+}
+
+func keys(pages map[string]*Page) []string {
+	var urls []string
+	for u := range pages {
+		urls = append(urls, u)
+	}
+	slices.Sort(urls)
+	return urls
+}
@@ -0,0 +1,307 @@
+package ingest
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlPage is the readable content and links of an HTML document
+type htmlPage struct {
+	title     string
+	canonical string
+	text      string
+	links     []string
+	noindex   bool
+	nofollow  bool
+}
+
+// boilerplate elements never contain page content (메뉴, 머리글/바닥글, 스크립트 등)
+var boilerplate = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Head:     true,
+}
+
+var boilerplateRoles = map[string]bool{
+	"navigation":    true,
+	"banner":        true,
+	"contentinfo":   true,
+	"complementary": true,
+	"search":        true,
+}
+
+// block elements start a new paragraph in the extracted text
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true,
+	atom.Table: true, atom.Blockquote: true, atom.Pre: true, atom.Figure: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Hr: true, atom.Details: true, atom.Summary: true,
+}
+
+// parseHTML extracts the main text of a page and the links it contains.
+// 링크는 nav 등을 포함한 문서 전체에서, 본문은 <main>/<article>이 있으면 그 안에서만 가져온다.
+func parseHTML(r io.Reader, base *url.URL) (*htmlPage, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &htmlPage{}
+	var main *html.Node
+	var h1 string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if page.title == "" {
+					page.title = collapseSpace(textOf(n))
+				}
+			case atom.H1:
+				if h1 == "" {
+					h1 = collapseSpace(textOf(n))
+				}
+			case atom.Link:
+				if strings.EqualFold(attr(n, "rel"), "canonical") && page.canonical == "" {
+					page.canonical = resolve(base, attr(n, "href"))
+				}
+			case atom.Meta:
+				if strings.EqualFold(attr(n, "name"), "robots") {
+					content := strings.ToLower(attr(n, "content"))
+					page.noindex = page.noindex || strings.Contains(content, "noindex") || strings.Contains(content, "none")
+					page.nofollow = page.nofollow || strings.Contains(content, "nofollow") || strings.Contains(content, "none")
+				}
+			case atom.Base:
+				if href := resolve(base, attr(n, "href")); href != "" {
+					base, _ = url.Parse(href)
+				}
+			case atom.A:
+				if !strings.Contains(strings.ToLower(attr(n, "rel")), "nofollow") {
+					if link := resolve(base, attr(n, "href")); link != "" {
+						page.links = append(page.links, link)
+					}
+				}
+			case atom.Main, atom.Article:
+				if main == nil {
+					main = n
+				}
+			}
+			if main == nil && strings.EqualFold(attr(n, "role"), "main") {
+				main = n
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if page.title == "" {
+		page.title = h1
+	}
+	content := main
+	if content == nil {
+		content = doc
+	}
+	var b textBuilder
+	b.node(content)
+	page.text = b.String()
+	return page, nil
+}
+
+// textBuilder renders nodes as plain text with paragraph breaks (제목은 markdown heading으로)
+type textBuilder struct {
+	sb  strings.Builder
+	pre int
+	// space는 다음 text 앞에 공백이 필요한지 (HTML 공백 병합)
+	space bool
+}
+
+func (b *textBuilder) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if b.pre > 0 {
+			b.sb.WriteString(n.Data)
+			b.space = false
+		} else {
+			b.inline(n.Data)
+		}
+		return
+	case html.ElementNode:
+		if boilerplate[n.DataAtom] || boilerplateRoles[strings.ToLower(attr(n, "role"))] ||
+			attr(n, "aria-hidden") == "true" || hasAttr(n, "hidden") {
+			return
+		}
+	case html.CommentNode, html.DoctypeNode:
+		return
+	}
+
+	block := n.Type == html.ElementNode && blockElements[n.DataAtom]
+	if block {
+		b.paragraph()
+	}
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		b.sb.WriteString(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+	case atom.Li:
+		b.line()
+		b.sb.WriteString("- ")
+	case atom.Tr, atom.Dt, atom.Dd:
+		b.line()
+	case atom.Br:
+		b.sb.WriteString("\n")
+		b.space = false
+	case atom.Td, atom.Th:
+		b.inline(" ")
+	case atom.Img:
+		// 이미지는 alt text만
+		b.inline(attr(n, "alt"))
+	case atom.Pre:
+		b.pre++
+		defer func() { b.pre-- }()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.node(c)
+	}
+	if block {
+		b.paragraph()
+	}
+}
+
+// inline appends text, collapsing whitespace as a browser would
+func (b *textBuilder) inline(s string) {
+	if s == "" {
+		return
+	}
+	if strings.TrimLeft(s, " \t\r\n") != s {
+		b.space = true
+	}
+	collapsed := collapseSpace(s)
+	if collapsed == "" {
+		return
+	}
+	current := b.sb.String()
+	if b.space && current != "" && !strings.HasSuffix(current, "\n") && !strings.HasSuffix(current, " ") {
+		b.sb.WriteByte(' ')
+	}
+	b.sb.WriteString(collapsed)
+	b.space = strings.TrimRight(s, " \t\r\n") != s
+}
+
+// line starts a new line (목록 항목, 표의 행)
+func (b *textBuilder) line() {
+	b.space = false
+	if current := b.sb.String(); current != "" && !strings.HasSuffix(current, "\n") {
+		b.sb.WriteString("\n")
+	}
+}
+
+func (b *textBuilder) paragraph() {
+	b.space = false
+	current := b.sb.String()
+	if current == "" || strings.HasSuffix(current, "\n\n") {
+		return
+	}
+	if strings.HasSuffix(current, "\n") {
+		b.sb.WriteString("\n")
+		return
+	}
+	b.sb.WriteString("\n\n")
+}
+
+// String returns the text without empty headings and list items
+func (b *textBuilder) String() string {
+	var paras []string
+	for _, para := range strings.Split(b.sb.String(), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" || strings.Trim(para, "#- ") == "" {
+			continue
+		}
+		paras = append(paras, para)
+	}
+	return strings.Join(paras, "\n\n")
+}
+
+func textOf(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textOf(c))
+	}
+	return sb.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// resolve returns href as an absolute, normalized http(s) URL ("" if it is not crawlable)
+func resolve(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	return normalizeURL(ref)
+}
+
+// normalizeURL drops the fragment and default port so the same page has one key
+func normalizeURL(u *url.URL) string {
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return ""
+	}
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	if port := n.Port(); (n.Scheme == "http" && port == "80") || (n.Scheme == "https" && port == "443") {
+		n.Host = n.Hostname()
+	}
+	n.Fragment = ""
+	n.RawFragment = ""
+	n.User = nil
+	if n.Path == "" {
+		n.Path = "/"
+	}
+	return n.String()
+}
@@ -0,0 +1,334 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+
+	"example.com/hello/embedding"
+	"example.com/hello/vector"
+)
+
+// Record formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// DefaultBatchSize is the number of rows embedded and inserted together
+const DefaultBatchSize = 32
+
+// MetaRow is the line number of the row a document was made from
+const MetaRow = "row"
+
+// ErrInvalidImport marks errors in the import options or file header (요청을 고쳐야 하는 오류)
+var ErrInvalidImport = errors.New("invalid import")
+
+// RecordOptions maps the rows of a CSV or JSONL file to documents
+type RecordOptions struct {
+	TenantID   string
+	Collection string
+	Format     string
+	// Content는 row를 문서 내용으로 만드는 text/template (예: "Q: {{.question}}\nA: {{.answer}}")
+	Content string
+	// Metadata는 metadata key → field 이름 (JSONL은 "product.sku"처럼 중첩 field 가능)
+	Metadata map[string]string
+	// Source는 metadata source_root로 저장되는 이름 (보통 파일 이름)
+	Source        string
+	AllowedUsers  []string
+	AllowedGroups []string
+	// BatchSize가 0이면 DefaultBatchSize
+	BatchSize int
+	// DryRun이면 template만 적용하고 DB는 바꾸지 않는다
+	DryRun bool
+}
+
+// RecordReport lists what one import stored
+type RecordReport struct {
+	Rows     int        `json:"rows"`
+	Inserted int        `json:"inserted"`
+	Failed   int        `json:"failed"`
+	IDs      []int      `json:"ids,omitempty"`
+	Errors   []RowError `json:"errors,omitempty"`
+}
+
+// RowError is a row that could not be imported
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// maxRowErrors limits the errors kept in a report (전부 실패하는 큰 파일에서 report가 커지지 않도록)
+const maxRowErrors = 1000
+
+// RecordIngester imports structured rows as documents.
+// 파일 전체를 메모리에 올리지 않고 한 줄씩 읽어 batch 단위로 embedding/저장한다.
+type RecordIngester struct {
+	db  *vector.VectorDB
+	emb *embedding.Service
+}
+
+// NewRecordIngester creates a new record ingester
+func NewRecordIngester(db *vector.VectorDB, emb *embedding.Service) *RecordIngester {
+	return &RecordIngester{db: db, emb: emb}
+}
+
+// FormatOf guesses the format from a file name ("" if unknown)
+func FormatOf(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+	return ""
+}
+
+type row struct {
+	line int
+	doc  vector.Document
+}
+
+// Ingest reads rows from r and stores one document per row.
+// 잘못된 row는 report에 기록하고 건너뛴다. embedding이나 DB 오류는 중단하고 그때까지의 report와 함께 반환한다.
+// template, format, CSV header가 잘못되면 ErrInvalidImport를 반환한다.
+func (ri *RecordIngester) Ingest(ctx context.Context, r io.Reader, opts RecordOptions) (*RecordReport, error) {
+	if strings.TrimSpace(opts.Content) == "" {
+		return nil, fmt.Errorf("%w: content template is required", ErrInvalidImport)
+	}
+	tmpl, err := template.New("content").Option("missingkey=error").Parse(opts.Content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: content template: %w", ErrInvalidImport, err)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	var next func() (int, map[string]any, error)
+	switch opts.Format {
+	case FormatCSV:
+		next, err = csvRows(r)
+		if err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		next = jsonlRows(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q (csv, jsonl)", ErrInvalidImport, opts.Format)
+	}
+
+	report := &RecordReport{}
+	rowError := func(line int, err error) {
+		report.Failed++
+		if len(report.Errors) < maxRowErrors {
+			report.Errors = append(report.Errors, RowError{Line: line, Error: err.Error()})
+		}
+	}
+
+	batch := make([]row, 0, opts.BatchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		line, fields, err := next()
+		if err == io.EOF {
+			break
+		}
+		report.Rows++
+		if err != nil {
+			var fatal *fatalReadError
+			if errors.As(err, &fatal) {
+				return report, fatal.err
+			}
+			rowError(line, err)
+			continue
+		}
+
+		doc, err := buildDocument(tmpl, fields, opts)
+		if err != nil {
+			rowError(line, err)
+			continue
+		}
+		doc.Metadata[MetaRow] = line
+		batch = append(batch, row{line: line, doc: doc})
+
+		if len(batch) == opts.BatchSize {
+			if err := ri.flush(ctx, batch, opts, report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := ri.flush(ctx, batch, opts, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// flush embeds and inserts one batch in a single transaction
+func (ri *RecordIngester) flush(ctx context.Context, batch []row, opts RecordOptions, report *RecordReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if opts.DryRun {
+		report.Inserted += len(batch)
+		return nil
+	}
+
+	contents := make([]string, len(batch))
+	for i, r := range batch {
+		contents[i] = r.doc.Content
+	}
+	embeddings, err := ri.emb.GenerateBatchEmbeddings(ctx, contents)
+	if err != nil {
+		return fmt.Errorf("failed to embed rows %d-%d: %w", batch[0].line, batch[len(batch)-1].line, err)
+	}
+	docs := make([]vector.Document, len(batch))
+	for i, r := range batch {
+		docs[i] = r.doc
+		docs[i].Embedding = embeddings[i]
+	}
+	ids, err := ri.db.InsertDocuments(ctx, docs)
+	if err != nil {
+		return fmt.Errorf("failed to insert rows %d-%d: %w", batch[0].line, batch[len(batch)-1].line, err)
+	}
+	report.Inserted += len(ids)
+	report.IDs = append(report.IDs, ids...)
+	return nil
+}
+
+// buildDocument renders the content and collects the metadata of one row
+func buildDocument(tmpl *template.Template, fields map[string]any, opts RecordOptions) (vector.Document, error) {
+	var content bytes.Buffer
+	if err := tmpl.Execute(&content, fields); err != nil {
+		return vector.Document{}, fmt.Errorf("failed to render content: %w", err)
+	}
+	text := strings.TrimSpace(content.String())
+	if text == "" {
+		return vector.Document{}, errors.New("content is empty")
+	}
+
+	metadata := map[string]any{}
+	for key, field := range opts.Metadata {
+		value, ok := lookupField(fields, field)
+		if !ok {
+			return vector.Document{}, fmt.Errorf("metadata field %q is missing", field)
+		}
+		metadata[key] = value
+	}
+	if opts.Source != "" {
+		metadata[vector.MetaSourceRoot] = opts.Source
+	}
+
+	return vector.Document{
+		TenantID:      opts.TenantID,
+		Collection:    opts.Collection,
+		Content:       text,
+		AllowedUsers:  opts.AllowedUsers,
+		AllowedGroups: opts.AllowedGroups,
+		Metadata:      metadata,
+	}, nil
+}
+
+// lookupField returns a field by name, following dots into nested objects
+func lookupField(fields map[string]any, name string) (any, bool) {
+	if v, ok := fields[name]; ok {
+		return v, true
+	}
+	var current any = fields
+	for _, part := range strings.Split(name, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// fatalReadError stops the import (읽기 자체가 불가능한 경우)
+type fatalReadError struct {
+	err error
+}
+
+func (e *fatalReadError) Error() string { return e.err.Error() }
+
+// csvRows reads the header and returns a reader of the following rows keyed by column name
+func csvRows(r io.Reader) (func() (int, map[string]any, error), error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %w", ErrInvalidImport, err)
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	}
+
+	return func() (int, map[string]any, error) {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return 0, nil, &fatalReadError{err: fmt.Errorf("failed to read CSV: %w", err)}
+			}
+			return parseErr.StartLine, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		fields := make(map[string]any, len(columns))
+		for i, name := range columns {
+			fields[name] = record[i]
+		}
+		return line, fields, nil
+	}, nil
+}
+
+// jsonlRows returns a reader of JSON objects, one per line (빈 줄은 건너뛴다)
+func jsonlRows(r io.Reader) func() (int, map[string]any, error) {
+	reader := bufio.NewReader(r)
+	line := 0
+	return func() (int, map[string]any, error) {
+		for {
+			data, err := reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return line, nil, &fatalReadError{err: fmt.Errorf("failed to read JSONL: %w", err)}
+			}
+			if len(data) == 0 && err == io.EOF {
+				return 0, nil, io.EOF
+			}
+			line++
+			data = bytes.TrimSpace(data)
+			if len(data) == 0 {
+				if err == io.EOF {
+					return 0, nil, io.EOF
+				}
+				continue
+			}
+
+			var fields map[string]any
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&fields); err != nil {
+				return line, nil, fmt.Errorf("invalid JSON: %w", err)
+			}
+			if fields == nil {
+				return line, nil, errors.New("row is not a JSON object")
+			}
+			return line, fields, nil
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ingest
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+
+	"example.com/hello/vector"
+)
+
+type parsedRow struct {
+	line   int
+	fields map[string]any
+	err    error
+}
+
+func readAll(next func() (int, map[string]any, error)) []parsedRow {
+	var rows []parsedRow
+	for {
+		line, fields, err := next()
+		if err == io.EOF {
+			return rows
+		}
+		rows = append(rows, parsedRow{line, fields, err})
+	}
+}
+
+func TestCSVRows(t *testing.T) {
+	input := "\ufeffquestion, answer\n" +
+		"q1,a1\n" +
+		"q2,\"multi\nline\"\n" +
+		"too,many,fields\n" +
+		"q4,a4\n"
+	next, err := csvRows(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("csvRows: %v", err)
+	}
+	rows := readAll(next)
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(rows))
+	}
+
+	if rows[0].line != 2 || rows[0].fields["question"] != "q1" || rows[0].fields["answer"] != "a1" {
+		t.Errorf("row 1 = %+v, want line 2 with trimmed column names", rows[0])
+	}
+	if rows[1].line != 3 || rows[1].fields["answer"] != "multi\nline" {
+		t.Errorf("row 2 = %+v, want the quoted field starting on line 3", rows[1])
+	}
+	if rows[2].line != 5 || !errors.Is(rows[2].err, csv.ErrFieldCount) {
+		t.Errorf("row 3 = %+v, want a field count error on line 5", rows[2])
+	}
+	if rows[3].line != 6 || rows[3].err != nil {
+		t.Errorf("row 4 = %+v, want line 6 after the bad row", rows[3])
+	}
+}
+
+func TestCSVRowsEmpty(t *testing.T) {
+	if _, err := csvRows(strings.NewReader("")); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("err = %v, want ErrInvalidImport", err)
+	}
+}
+
+func TestJSONLRows(t *testing.T) {
+	input := `{"question": "q1", "n": 3}` + "\n" +
+		"\n" +
+		`{"question": ` + "\n" +
+		`[1, 2]` + "\n" +
+		`null` + "\n" +
+		`{"question": "last"}`
+	rows := readAll(jsonlRows(strings.NewReader(input)))
+
+	want := []struct {
+		line int
+		err  string
+	}{
+		{1, ""},
+		{3, "invalid JSON"},
+		{4, "invalid JSON"},
+		{5, "row is not a JSON object"},
+		{6, ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i, w := range want {
+		got := rows[i]
+		if got.line != w.line || (w.err == "") != (got.err == nil) || (got.err != nil && !strings.Contains(got.err.Error(), w.err)) {
+			t.Errorf("row %d = line %d, err %v; want line %d, err %q", i, got.line, got.err, w.line, w.err)
+		}
+	}
+	if n, ok := rows[0].fields["n"].(json.Number); !ok || n != "3" {
+		t.Errorf("numbers should be kept as json.Number, got %T %v", rows[0].fields["n"], rows[0].fields["n"])
+	}
+	if rows[4].fields["question"] != "last" {
+		t.Errorf("last line without newline = %+v", rows[4])
+	}
+}
+
+func TestBuildDocument(t *testing.T) {
+	tmpl := template.Must(template.New("content").Option("missingkey=error").Parse("Q: {{.question}}\nA: {{.answer}}"))
+	opts := RecordOptions{
+		TenantID:     "t",
+		Collection:   "faq",
+		Source:       "faq.jsonl",
+		AllowedUsers: []string{"alice"},
+		Metadata:     map[string]string{"sku": "product.sku", "dotted": "a.b"},
+	}
+	fields := map[string]any{
+		"question": "q",
+		"answer":   "a",
+		"product":  map[string]any{"sku": "X-1"},
+		"a.b":      "literal key",
+	}
+
+	doc, err := buildDocument(tmpl, fields, opts)
+	if err != nil {
+		t.Fatalf("buildDocument: %v", err)
+	}
+	if doc.Content != "Q: q\nA: a" || doc.TenantID != "t" || doc.Collection != "faq" || !slices.Equal(doc.AllowedUsers, []string{"alice"}) {
+		t.Errorf("doc = %+v", doc)
+	}
+	if doc.Metadata["sku"] != "X-1" || doc.Metadata["dotted"] != "literal key" || doc.Metadata[vector.MetaSourceRoot] != "faq.jsonl" {
+		t.Errorf("metadata = %v", doc.Metadata)
+	}
+
+	concat := template.Must(template.New("content").Parse("{{.question}}{{.answer}}"))
+	for name, tc := range map[string]struct {
+		tmpl   *template.Template
+		fields map[string]any
+		err    string
+	}{
+		"missing template field": {tmpl, map[string]any{"question": "q", "product": map[string]any{"sku": 1}}, "failed to render content"},
+		"empty content":          {concat, map[string]any{"question": " ", "answer": "", "product": map[string]any{"sku": 1}}, "content is empty"},
+		"missing metadata field": {tmpl, map[string]any{"question": "q", "answer": "a", "product": "not an object"}, `metadata field "product.sku" is missing`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := opts
+			opts.Metadata = map[string]string{"sku": "product.sku"}
+			if _, err := buildDocument(tc.tmpl, tc.fields, opts); err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("err = %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+// TestIngestRowErrors runs a dry-run import and checks the reported line numbers
+func TestIngestRowErrors(t *testing.T) {
+	input := "question,answer\n" +
+		"q1,a1\n" +
+		"q2\n" +
+		" , \n" +
+		"q4,a4\n"
+	report, err := NewRecordIngester(nil, nil).Ingest(context.Background(), strings.NewReader(input), RecordOptions{
+		Format:  FormatCSV,
+		Content: "{{.question}} {{.answer}}",
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if report.Rows != 4 || report.Inserted != 2 || report.Failed != 2 {
+		t.Errorf("report = %+v, want 4 rows, 2 inserted, 2 failed", report)
+	}
+	var lines []int
+	for _, e := range report.Errors {
+		lines = append(lines, e.Line)
+	}
+	if !slices.Equal(lines, []int{3, 4}) {
+		t.Errorf("error lines = %v, want [3 4]", lines)
+	}
+}
+
+func TestIngestInvalidOptions(t *testing.T) {
+	ri := NewRecordIngester(nil, nil)
+	for name, opts := range map[string]RecordOptions{
+		"no template":  {Format: FormatCSV},
+		"bad template": {Format: FormatCSV, Content: "{{.question"},
+		"bad format":   {Format: "xml", Content: "{{.question}}"},
+	} {
+		if _, err := ri.Ingest(context.Background(), strings.NewReader("question\nq\n"), opts); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("%s: err = %v, want ErrInvalidImport", name, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ingest
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robots is the robots.txt group that applies to the crawler (RFC 9309)
+type robots struct {
+	rules []robotsRule
+	// delay는 Crawl-delay (비표준이지만 wiki 서버들이 많이 사용)
+	delay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+var (
+	allowAll    = &robots{}
+	disallowAll = &robots{rules: []robotsRule{{allow: false, pattern: "/"}}}
+)
+
+// parseRobots returns the rules of the group matching agent, or of "*" if none does
+func parseRobots(r io.Reader, agent string) *robots {
+	agent = strings.ToLower(agent)
+
+	type group struct {
+		agents []string
+		robots robots
+	}
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, 512<<10))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// 연속된 User-agent 줄은 같은 group
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil || (value == "" && key == "disallow") {
+				continue
+			}
+			current.robots.rules = append(current.robots.rules, robotsRule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.robots.delay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	// 이름이 가장 길게 일치하는 group, 없으면 "*"
+	var best *group
+	bestLen := -1
+	for _, g := range groups {
+		for _, a := range g.agents {
+			n := -1
+			switch {
+			case a == "*":
+				n = 0
+			case a != "" && strings.Contains(agent, a):
+				n = len(a)
+			}
+			if n > bestLen {
+				best, bestLen = g, n
+			}
+		}
+	}
+	if best == nil {
+		return allowAll
+	}
+	return &best.robots
+}
+
+// allowed reports whether path (with query) may be fetched. 가장 길게 일치하는 규칙이 이기고, 같으면 allow
+func (r *robots) allowed(path string) bool {
+	allow := true
+	matched := -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		n := len(rule.pattern)
+		if n > matched || (n == matched && rule.allow) {
+			allow, matched = rule.allow, n
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches a robots.txt path pattern ('*' wildcard, '$' end anchor)
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
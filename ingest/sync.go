@@ -0,0 +1,333 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"example.com/hello/vector"
+)
+
+// maxFileSize skips files that are too large to be documentation
+const maxFileSize = 5 << 20
+
+// DefaultInclude are the file name patterns synced when none are given
+var DefaultInclude = []string{"*.md", "*.markdown", "*.txt"}
+
+// SyncOptions selects the directory and where its files are stored
+type SyncOptions struct {
+	TenantID   string
+	Collection string
+	// Root는 동기화할 디렉터리. Git이면 git ls-files로 추적 중인 파일만 대상
+	Root string
+	Git  bool
+	// Source는 source_root로 저장할 이름. 비어 있으면 Git이면 origin remote URL, 아니면 Root의 절대 경로
+	// (checkout 위치가 달라도 같은 이름을 쓰면 문서가 중복되지 않는다)
+	Source  string
+	Include []string
+	// DryRun이면 변경 사항만 계산하고 DB는 바꾸지 않는다
+	DryRun bool
+}
+
+// SyncReport lists what one sync changed
+type SyncReport struct {
+	Root      string      `json:"root"`
+	Commit    string      `json:"commit,omitempty"`
+	Added     []string    `json:"added"`
+	Updated   []string    `json:"updated"`
+	Deleted   []string    `json:"deleted"`
+	Unchanged int         `json:"unchanged"`
+	Chunks    int         `json:"chunks"`
+	Errors    []FileError `json:"errors,omitempty"`
+}
+
+// FileError is a file that could not be synced
+type FileError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Syncer mirrors a local directory or git checkout into a collection.
+// 파일은 source_root(SyncOptions.Source) + source_path로 식별하므로 같은 source를 다시 동기화해도 중복되지 않는다.
+type Syncer struct {
+	db     SourceStore
+	writer *Writer
+}
+
+// NewSyncer creates a new directory syncer that stores files with writer
+func NewSyncer(db SourceStore, writer *Writer) *Syncer {
+	return &Syncer{db: db, writer: writer}
+}
+
+type sourceFile struct {
+	path  string // root 기준 상대 경로 (/ 구분)
+	abs   string
+	mtime string
+}
+
+// Sync adds changed files and deletes removed ones.
+// 파일 하나의 실패는 report에 기록하고 나머지 파일은 계속 처리한다.
+func (s *Syncer) Sync(ctx context.Context, opts SyncOptions) (*SyncReport, error) {
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", opts.Root, err)
+	}
+	if len(opts.Include) == 0 {
+		opts.Include = DefaultInclude
+	}
+	source := opts.Source
+	if source == "" && opts.Git {
+		source = gitRemote(ctx, root)
+	}
+	if source == "" {
+		source = filepath.ToSlash(root)
+	}
+	report := &SyncReport{Root: source, Added: []string{}, Updated: []string{}, Deleted: []string{}}
+
+	var files []sourceFile
+	if opts.Git {
+		report.Commit, err = gitOutput(ctx, root, "rev-parse", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		files, err = gitFiles(ctx, root, opts.Include)
+	} else {
+		files, err = walkFiles(root, opts.Include)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.db.Sources(ctx, opts.TenantID, opts.Collection, report.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		seen[f.path] = true
+		if err := s.syncFile(ctx, opts, report, f, stored); err != nil {
+			slog.Warn("Failed to sync file", "path", f.path, "error", err)
+			report.Errors = append(report.Errors, FileError{Path: f.path, Error: err.Error()})
+		}
+	}
+
+	// 디렉터리에서 사라진 파일의 문서 삭제
+	for p := range stored {
+		if seen[p] {
+			continue
+		}
+		if !opts.DryRun {
+			if _, err := s.db.DeleteSource(ctx, opts.TenantID, opts.Collection, report.Root, p); err != nil {
+				report.Errors = append(report.Errors, FileError{Path: p, Error: err.Error()})
+				continue
+			}
+		}
+		report.Deleted = append(report.Deleted, p)
+	}
+
+	// 이전에는 절대 경로를 source_root로 저장했으므로, 다른 이름으로 동기화하면 그 경로의 문서는 정리
+	if legacy := filepath.ToSlash(root); source != legacy && !opts.DryRun {
+		s.deleteRoot(ctx, opts, report, legacy)
+	}
+	return report, nil
+}
+
+// deleteRoot deletes every source stored under root
+func (s *Syncer) deleteRoot(ctx context.Context, opts SyncOptions, report *SyncReport, root string) {
+	stored, err := s.db.Sources(ctx, opts.TenantID, opts.Collection, root)
+	if err != nil {
+		report.Errors = append(report.Errors, FileError{Path: root, Error: err.Error()})
+		return
+	}
+	for p := range stored {
+		if _, err := s.db.DeleteSource(ctx, opts.TenantID, opts.Collection, root, p); err != nil {
+			report.Errors = append(report.Errors, FileError{Path: root + "/" + p, Error: err.Error()})
+			continue
+		}
+		slog.Info("Deleted document stored under the checkout path", "root", root, "path", p, "source", report.Root)
+	}
+}
+
+// syncFile re-embeds a file when its content changed (mtime이 같으면 내용을 읽지 않는다)
+func (s *Syncer) syncFile(ctx context.Context, opts SyncOptions, report *SyncReport, f sourceFile, stored map[string]vector.SourceState) error {
+	prev, exists := stored[f.path]
+	if exists && prev.MTime == f.mtime {
+		report.Unchanged++
+		return nil
+	}
+
+	data, err := os.ReadFile(f.abs)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxFileSize {
+		return fmt.Errorf("file is larger than %d bytes", maxFileSize)
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return fmt.Errorf("file is not UTF-8 text")
+	}
+	hash := contentHash(data)
+
+	// 내용이 같으면 (checkout 등으로 mtime만 바뀐 경우) embedding 없이 mtime만 갱신
+	if exists && prev.Hash == hash {
+		report.Unchanged++
+		if opts.DryRun {
+			return nil
+		}
+		return s.db.TouchSource(ctx, opts.TenantID, opts.Collection, report.Root, f.path, f.mtime)
+	}
+
+	var chunks int
+	if opts.DryRun {
+		chunks = len(Chunk(string(data), s.writer.ChunkSize, s.writer.ChunkOverlap))
+	} else {
+		metadata := map[string]any{
+			vector.MetaSourceHash:  hash,
+			vector.MetaSourceMTime: f.mtime,
+		}
+		if title := markdownTitle(string(data)); title != "" {
+			metadata[vector.MetaTitle] = title
+		}
+		if report.Commit != "" {
+			metadata["git_commit"] = report.Commit
+		}
+		if chunks, err = s.writer.Replace(ctx, opts.TenantID, opts.Collection, report.Root, f.path, string(data), metadata); err != nil {
+			return err
+		}
+	}
+	if chunks == 0 {
+		// 빈 파일은 문서가 없는 것과 같다
+		if exists {
+			report.Deleted = append(report.Deleted, f.path)
+		}
+		return nil
+	}
+
+	report.Chunks += chunks
+	if exists {
+		report.Updated = append(report.Updated, f.path)
+	} else {
+		report.Added = append(report.Added, f.path)
+	}
+	return nil
+}
+
+// walkFiles lists matching regular files under root, skipping hidden directories (.git 등)
+func walkFiles(root string, include []string) ([]sourceFile, error) {
+	var files []sourceFile
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matches(d.Name(), include) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, sourceFile{path: filepath.ToSlash(rel), abs: p, mtime: formatMTime(info.ModTime())})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// gitFiles lists matching files tracked by git (.gitignore된 파일과 untracked 파일 제외)
+func gitFiles(ctx context.Context, root string, include []string) ([]sourceFile, error) {
+	out, err := gitOutput(ctx, root, "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []sourceFile
+	for _, rel := range strings.Split(out, "\x00") {
+		if rel == "" || !matches(path.Base(rel), include) {
+			continue
+		}
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		info, err := os.Stat(abs)
+		if err != nil {
+			// 작업 트리에서 지워졌지만 아직 commit되지 않은 파일
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, sourceFile{path: rel, abs: abs, mtime: formatMTime(info.ModTime())})
+	}
+	return files, nil
+}
+
+// gitRemote returns the origin URL without credentials ("" if there is no origin)
+func gitRemote(ctx context.Context, root string) string {
+	remote, err := gitOutput(ctx, root, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	// git@host:org/repo.git 같은 scp 형식은 url로 파싱되지 않으므로 그대로 사용
+	if u, err := url.Parse(remote); err == nil && u.User != nil {
+		u.User = nil
+		return u.String()
+	}
+	return remote
+}
+
+func gitOutput(ctx context.Context, root string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", root}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func matches(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func formatMTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// markdownTitle returns the first top-level heading (citation 표시용)
+func markdownTitle(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
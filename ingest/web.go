@@ -0,0 +1,154 @@
+package ingest
+
+import (
+	"context"
+	"net/url"
+
+	"example.com/hello/vector"
+)
+
+// WebOptions selects the pages to crawl and where they are stored
+type WebOptions struct {
+	CrawlOptions
+	TenantID   string
+	Collection string
+	// DryRun이면 crawl만 하고 DB는 바꾸지 않는다
+	DryRun bool
+}
+
+// WebReport lists what one crawl changed
+type WebReport struct {
+	*CrawlReport
+	Added     []string `json:"added"`
+	Updated   []string `json:"updated"`
+	Deleted   []string `json:"deleted"`
+	Unchanged int      `json:"unchanged"`
+	Chunks    int      `json:"chunks"`
+}
+
+// WebIngester crawls pages into a collection.
+// 페이지는 source_root(scheme://host) + source_path(canonical URL)로 식별하고 url metadata로 citation에 링크된다.
+type WebIngester struct {
+	db      SourceStore
+	crawler *Crawler
+	writer  *Writer
+}
+
+// NewWebIngester creates a new web ingester
+func NewWebIngester(db SourceStore, crawler *Crawler, writer *Writer) *WebIngester {
+	return &WebIngester{db: db, crawler: crawler, writer: writer}
+}
+
+// Ingest crawls the seeds and stores pages whose text changed.
+// 내용 hash가 같은 페이지는 다시 embedding하지 않고, 404/410이 된 페이지는 삭제한다.
+func (w *WebIngester) Ingest(ctx context.Context, opts WebOptions) (*WebReport, error) {
+	report := &WebReport{Added: []string{}, Updated: []string{}, Deleted: []string{}}
+
+	// host별로 저장된 페이지 (필요할 때 한 번만 조회)
+	stored := map[string]map[string]vector.SourceState{}
+	storedOf := func(ctx context.Context, root string) (map[string]vector.SourceState, error) {
+		if s, ok := stored[root]; ok {
+			return s, nil
+		}
+		s, err := w.db.Sources(ctx, opts.TenantID, opts.Collection, root)
+		if err != nil {
+			return nil, err
+		}
+		stored[root] = s
+		return s, nil
+	}
+
+	// 이번 crawl에서 받아 온 페이지 (canonical URL)
+	visited := map[string]bool{}
+
+	crawl, err := w.crawler.Crawl(ctx, opts.CrawlOptions, func(ctx context.Context, page *Page) error {
+		visited[page.URL] = true
+		root := siteRoot(page.URL)
+		sources, err := storedOf(ctx, root)
+		if err != nil {
+			return err
+		}
+		hash := contentHash([]byte(page.Text))
+		prev, exists := sources[page.URL]
+		if exists && prev.Hash == hash {
+			report.Unchanged++
+			return nil
+		}
+
+		chunks := 0
+		if opts.DryRun {
+			chunks = len(Chunk(page.Text, w.writer.ChunkSize, w.writer.ChunkOverlap))
+		} else {
+			metadata := map[string]any{
+				vector.MetaSourceHash: hash,
+				vector.MetaURL:        page.URL,
+				vector.MetaFetchedURL: page.Fetched,
+			}
+			if page.Title != "" {
+				metadata[vector.MetaTitle] = page.Title
+			}
+			if chunks, err = w.writer.Replace(ctx, opts.TenantID, opts.Collection, root, page.URL, page.Text, metadata); err != nil {
+				return err
+			}
+		}
+		sources[page.URL] = vector.SourceState{Path: page.URL, Hash: hash, Fetched: page.Fetched, Chunks: chunks}
+
+		report.Chunks += chunks
+		if exists {
+			report.Updated = append(report.Updated, page.URL)
+		} else {
+			report.Added = append(report.Added, page.URL)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.CrawlReport = crawl
+
+	// 없어진 페이지 삭제 (limit 때문에 방문하지 못한 페이지는 그대로 둔다).
+	// Gone은 요청한 URL이므로 canonical URL로 저장된 페이지는 fetched_url로 찾는다.
+	for _, gone := range crawl.Gone {
+		root := siteRoot(gone)
+		sources, err := storedOf(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+		path := storedPage(sources, gone)
+		// 다른 URL로 같은 canonical 페이지를 이번에 받았다면 아직 있는 페이지
+		if path == "" || visited[path] {
+			continue
+		}
+		if !opts.DryRun {
+			if _, err := w.db.DeleteSource(ctx, opts.TenantID, opts.Collection, root, path); err != nil {
+				crawl.Errors = append(crawl.Errors, FileError{Path: path, Error: err.Error()})
+				continue
+			}
+		}
+		delete(sources, path)
+		report.Deleted = append(report.Deleted, path)
+	}
+	return report, nil
+}
+
+// storedPage returns the source_path of the stored page fetched from target ("" if none)
+func storedPage(sources map[string]vector.SourceState, target string) string {
+	if _, ok := sources[target]; ok {
+		return target
+	}
+	for path, s := range sources {
+		if s.Fetched == target {
+			return path
+		}
+	}
+	return ""
+}
+
+// siteRoot returns the scheme://host of a page URL
+func siteRoot(page string) string {
+	u, err := url.Parse(page)
+	if err != nil {
+		return page
+	}
+	return u.Scheme + "://" + u.Host
+}
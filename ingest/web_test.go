@@ -0,0 +1,24 @@
+package ingest
+
+import (
+	"testing"
+
+	"example.com/hello/vector"
+)
+
+func TestStoredPage(t *testing.T) {
+	sources := map[string]vector.SourceState{
+		"https://example.com/doc":   {Path: "https://example.com/doc", Fetched: "https://example.com/doc?ref=nav"},
+		"https://example.com/guide": {Path: "https://example.com/guide"},
+	}
+	tests := map[string]string{
+		"https://example.com/doc?ref=nav": "https://example.com/doc",
+		"https://example.com/guide":       "https://example.com/guide",
+		"https://example.com/doc?ref=old": "",
+	}
+	for gone, want := range tests {
+		if got := storedPage(sources, gone); got != want {
+			t.Errorf("storedPage(%q) = %q, want %q", gone, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ingest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+
+	"example.com/hello/vector"
+)
+
+// SourceStore stores the chunks of ingested sources (vector.VectorDB)
+type SourceStore interface {
+	Sources(ctx context.Context, tenantID, collection, root string) (map[string]vector.SourceState, error)
+	ReplaceSource(ctx context.Context, tenantID, collection, root, path string, docs []vector.Document) ([]int, error)
+	DeleteSource(ctx context.Context, tenantID, collection, root, path string) (int64, error)
+	TouchSource(ctx context.Context, tenantID, collection, root, path, mtime string) error
+}
+
+// Embedder embeds document chunks (embedding.Service)
+type Embedder interface {
+	GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Writer chunks, embeds and stores the text of one source (파일, 웹 페이지 등)
+type Writer struct {
+	db  SourceStore
+	emb Embedder
+	// ChunkSize, ChunkOverlap이 0이면 기본값
+	ChunkSize    int
+	ChunkOverlap int
+}
+
+// NewWriter creates a new source writer
+func NewWriter(db SourceStore, emb Embedder) *Writer {
+	return &Writer{db: db, emb: emb}
+}
+
+// Replace stores text as the chunks of root/path, replacing its previous chunks.
+// metadata는 모든 chunk에 복사되고 source_root, source_path, chunk가 추가된다. 저장한 chunk 수를 반환한다.
+func (w *Writer) Replace(ctx context.Context, tenantID, collection, root, path, text string, metadata map[string]any) (int, error) {
+	chunks := Chunk(text, w.ChunkSize, w.ChunkOverlap)
+	if len(chunks) == 0 {
+		// 내용이 없으면 문서가 없는 것과 같다
+		_, err := w.db.DeleteSource(ctx, tenantID, collection, root, path)
+		return 0, err
+	}
+
+	embeddings, err := w.emb.GenerateBatchEmbeddings(ctx, chunks)
+	if err != nil {
+		return 0, err
+	}
+	docs := make([]vector.Document, len(chunks))
+	for i, chunk := range chunks {
+		meta := maps.Clone(metadata)
+		if meta == nil {
+			meta = map[string]any{}
+		}
+		meta[vector.MetaSourceRoot] = root
+		meta[vector.MetaSourcePath] = path
+		meta[vector.MetaChunk] = i
+		docs[i] = vector.Document{
+			TenantID:   tenantID,
+			Collection: collection,
+			Content:    chunk,
+			Embedding:  embeddings[i],
+			Metadata:   meta,
+		}
+	}
+	if _, err := w.db.ReplaceSource(ctx, tenantID, collection, root, path, docs); err != nil {
+		return 0, err
+	}
+	return len(chunks), nil
+}
+
+// contentHash identifies the content of a source (같으면 다시 embedding하지 않는다)
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
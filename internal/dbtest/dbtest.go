@@ -0,0 +1,59 @@
+// Package dbtest connects tests to the Postgres given in TEST_DATABASE_URL.
+// 환경 변수가 없으면 DB가 필요한 test는 건너뛴다 (pgvector가 설치된 Postgres 필요).
+package dbtest
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EnvDSN is the environment variable holding the test database connection string
+const EnvDSN = "TEST_DATABASE_URL"
+
+// timeout bounds connecting and migrating the test database
+const timeout = 30 * time.Second
+
+// Migrator is a store that creates its own tables
+type Migrator interface {
+	Migrate(ctx context.Context) error
+}
+
+// DSN returns the test database connection string, skipping t when it is not set
+func DSN(t testing.TB) string {
+	t.Helper()
+	dsn := os.Getenv(EnvDSN)
+	if dsn == "" {
+		t.Skip(EnvDSN + " is not set")
+	}
+	return dsn
+}
+
+// Pool connects to the test database and closes the pool when t finishes
+func Pool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	dsn := DSN(t)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// Migrate creates the tables of every store, in order
+func Migrate(t testing.TB, stores ...Migrator) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, store := range stores {
+		if err := store.Migrate(ctx); err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey struct{}
+
+type tenantKey struct{}
+
+var (
+	level     = new(slog.LevelVar)
+	debugMode atomic.Bool
+)
+
+// Setup installs a JSON slog logger as the default logger
+func Setup(w io.Writer, levelName string, debug bool) error {
+	if err := SetLevel(levelName); err != nil {
+		return err
+	}
+	debugMode.Store(debug)
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel changes the log level at runtime (debug, info, warn, error)
+func SetLevel(levelName string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(levelName))); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", levelName, err)
+	}
+	level.Set(l)
+	return nil
+}
+
+// Level returns the current log level name
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// SetDebug toggles debug mode, which disables redaction of user content
+func SetDebug(debug bool) {
+	debugMode.Store(debug)
+}
+
+// Debug reports whether debug mode is on
+func Debug() bool {
+	return debugMode.Load()
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// WithTenantID returns a context carrying the tenant ID for log lines
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the default logger annotated with the request ID,
+// tenant ID and trace ID in ctx
+func FromContext(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if id := RequestID(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if tenantID, _ := ctx.Value(tenantKey{}).(string); tenantID != "" {
+		logger = logger.With("tenant", tenantID)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		logger = logger.With("trace_id", sc.TraceID().String())
+	}
+	return logger
+}
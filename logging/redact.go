@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Text returns a log attribute for user content (질문, 문서, 프롬프트 등).
+// debug 모드가 아니면 길이만 남기고 내용은 가린다.
+func Text(key, value string) slog.Attr {
+	if Debug() {
+		return slog.String(key, value)
+	}
+	return slog.String(key, fmt.Sprintf("[redacted %d chars]", len([]rune(value))))
+}
+
+// Vector returns a log attribute for an embedding vector.
+// debug 모드에서도 앞 10개 값만 출력한다.
+func Vector(key string, value []float32) slog.Attr {
+	if !Debug() {
+		return slog.String(key, fmt.Sprintf("[redacted %d dims]", len(value)))
+	}
+	if len(value) > 10 {
+		return slog.String(key, fmt.Sprintf("%v... (%d dims)", value[:10], len(value)))
+	}
+	return slog.Any(key, value)
+}
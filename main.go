@@ -2,67 +2,356 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	"example.com/hello/audit"
+	"example.com/hello/auth"
 	"example.com/hello/chat"
 	"example.com/hello/config"
 	"example.com/hello/embedding"
+	"example.com/hello/eval"
+	"example.com/hello/feedback"
 	"example.com/hello/handler"
+	"example.com/hello/health"
+	"example.com/hello/logging"
+	"example.com/hello/metrics"
+	"example.com/hello/middleware"
+	"example.com/hello/prompt"
+	"example.com/hello/rag"
+	"example.com/hello/ratelimit"
 	"example.com/hello/reranker"
+	"example.com/hello/tenant"
+	"example.com/hello/tracing"
 	"example.com/hello/vector"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
-	// .env 로드
-	cfg, err := config.Load()
+	args := os.Args[1:]
+
+	// config print: 실제 적용되는 설정 출력 (secret은 마스킹)
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		cfg, err := config.Load(args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to load config:", err)
+			os.Exit(1)
+		}
+		if err := cfg.Print(os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// eval: golden dataset으로 검색/rerank 품질 측정
+	if len(args) >= 1 && args[0] == "eval" {
+		if err := runEval(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// sync: 디렉터리/git checkout의 문서를 collection에 동기화
+	if len(args) >= 1 && args[0] == "sync" {
+		if err := runSync(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// crawl: 웹 페이지(사내 wiki 등)를 수집해 collection에 저장
+	if len(args) >= 1 && args[0] == "crawl" {
+		if err := runCrawl(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// import: CSV/JSONL의 row를 문서로 저장
+	if len(args) >= 1 && args[0] == "import" {
+		if err := runImport(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 설정 로드 및 검증 (잘못된 설정이면 바로 종료)
+	cfg, err := config.Load(args)
+	if err != nil {
+		slog.Error("Failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	// JSON 구조화 로그 설정
+	if err := logging.Setup(os.Stdout, cfg.LogLevel, cfg.LogDebug); err != nil {
+		slog.Error("Failed to set up logging", "error", err)
+		os.Exit(1)
+	}
+	gin.SetMode(gin.ReleaseMode)
+
+	// OpenTelemetry tracing (none, stdout, otlp)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TraceExporter, cfg.ServiceName)
 	if err != nil {
-		log.Fatal("Failed to load config:", err)
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
 	}
 	// embedding api
 	// Embedding Service 생성
 	embService := embedding.NewService(cfg.EmbeddingAPIURL, cfg.EmbeddingModel)
-	log.Printf("✅ Embedding service initialized (URL: %s, Model: %s)\n", cfg.EmbeddingAPIURL, cfg.EmbeddingModel)
+	slog.Info("✅ Embedding service initialized", "url", cfg.EmbeddingAPIURL, "model", cfg.EmbeddingModel)
 
 	// reranker api
 	// Reranker Service 생성
 	rerankerService := reranker.NewService(cfg.RerankerAPIURL, cfg.RerankerModel)
-	log.Printf("✅ Reranker service initialized (URL: %s, Model: %s)\n", cfg.RerankerAPIURL, cfg.RerankerModel)
+	slog.Info("✅ Reranker service initialized", "url", cfg.RerankerAPIURL, "model", cfg.RerankerModel)
 
 	// llm chat api
 	// llm chat Service 생성
-	llmChatService := chat.NewService(cfg.LLMChatAPIURL, cfg.LLMChatModel)
-	log.Printf("✅ LLM Chat service initialized (URL: %s, Model: %s)\n", cfg.LLMChatAPIURL, cfg.LLMChatModel)
+	llmChatService := newChatService(cfg)
+	slog.Info("✅ LLM Chat service initialized", "url", cfg.LLMChatAPIURL, "model", cfg.LLMChatModel, "backend", cfg.LLMChatBackend)
+
+	// SIGINT/SIGTERM 수신 시 rootCtx 취소
+	rootCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// DB 연결
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(rootCtx, 10*time.Second)
 	defer cancel()
 
 	db, err := vector.New(ctx, cfg.GetDSN())
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
 	}
 	defer db.Close()
 
-	log.Println("✅ Database connected successfully")
+	slog.Info("✅ Database connected successfully")
+
+	if err := db.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate database", "error", err)
+		os.Exit(1)
+	}
+
+	// tenant store (기존 데이터는 default tenant 소속)
+	tenantStore := tenant.NewStore(db.Pool())
+	if err := tenantStore.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate tenant store", "error", err)
+		os.Exit(1)
+	}
+
+	// API key store
+	keyStore := auth.NewKeyStore(db.Pool(), cfg.AdminAPIKey)
+	if err := keyStore.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate API key store", "error", err)
+		os.Exit(1)
+	}
+
+	// prompt 템플릿 store (버전 관리, collection별 바인딩)
+	promptStore := prompt.NewStore(db.Pool())
+	if err := promptStore.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate prompt template store", "error", err)
+		os.Exit(1)
+	}
+
+	// judge 점수 store (live sampling, eval 결과의 trend)
+	scoreStore := eval.NewScoreStore(db.Pool())
+	if err := scoreStore.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate answer score store", "error", err)
+		os.Exit(1)
+	}
+
+	// 답변 trace, 사용자 feedback store
+	feedbackStore := feedback.NewStore(db.Pool())
+	if err := feedbackStore.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate feedback store", "error", err)
+		os.Exit(1)
+	}
+
+	// chat 감사 로그 (보관 기간이 지나면 삭제)
+	auditStore := audit.NewStore(db.Pool())
+	if err := auditStore.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate audit log", "error", err)
+		os.Exit(1)
+	}
+
+	// JWT_JWKS가 설정되면 OIDC access token도 허용
+	var jwtValidator *auth.JWTValidator
+	if cfg.JWKSSource != "" {
+		jwtValidator = auth.NewJWTValidator(auth.NewJWKS(cfg.JWKSSource, time.Hour), auth.JWTConfig{
+			Issuer:        cfg.JWTIssuer,
+			Audience:      cfg.JWTAudience,
+			GroupsClaim:   cfg.JWTGroupsClaim,
+			TenantClaim:   cfg.JWTTenantClaim,
+			DefaultScopes: cfg.JWTScopes,
+		})
+		slog.Info("✅ JWT authentication enabled", "jwks", cfg.JWKSSource)
+	}
+	authenticator := auth.NewAuthenticator(keyStore, jwtValidator)
+
+	// DB 관련 metrics 등록
+	prometheus.MustRegister(
+		metrics.NewPoolCollector(db.Stat),
+		metrics.NewDocumentCountCollector(db.CountByCollection, 30*time.Second),
+	)
 
 	// Handler 생성
-	docHandler := handler.NewDocumentHandler(db, embService, rerankerService, llmChatService)
+	// 요청 제한: client별 token bucket, LLM 동시 호출 제한, 일일 토큰 quota
+	rateLimiter := ratelimit.NewLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst)
+	llmLimiter := ratelimit.NewConcurrencyLimiter(cfg.LLMMaxConcurrency, cfg.LLMMaxQueue, cfg.LLMQueueTimeout)
+	quota := ratelimit.NewQuotaTracker(db.Pool(), cfg.DailyTokenQuota)
+	if err := quota.Migrate(ctx); err != nil {
+		slog.Error("Failed to migrate token usage", "error", err)
+		os.Exit(1)
+	}
+
+	// threshold, prompt, model, rate limit, log level은 SIGHUP/파일 변경 시 재시작 없이 반영
+	runtimeConfig := config.NewManager(cfg, args)
+	runtimeConfig.OnChange(func(rt config.Runtime) {
+		if err := logging.SetLevel(rt.LogLevel); err != nil {
+			slog.Warn("Failed to apply log level", "error", err)
+		}
+		rateLimiter.SetLimit(rt.RateLimitRPS, rt.RateLimitBurst)
+		quota.SetDailyLimit(rt.DailyTokenQuota)
+	})
+
+	// retrieve → rerank → chat pipeline (documents/chat, OpenAI 호환 API 공용)
+	pipeline := rag.NewPipeline(db, embService, rerankerService, llmChatService, runtimeConfig, promptStore)
+
+	// 모든 답변을 감사 로그에 기록하고, trace를 저장해 feedback과 연결
+	pipeline.OnResult(auditStore.Observe)
+	pipeline.OnResult(feedbackStore.Observe)
+
+	// 답변 일부(JUDGE_SAMPLE_RATE)를 judge 모델로 background 채점 (live 요청과 LLM concurrency limit 공유)
+	judge := eval.NewJudge(newJudgeService(cfg), judgeModel(cfg), promptStore)
+	sampler := eval.NewSampler(judge, scoreStore, llmLimiter, func() float64 {
+		return runtimeConfig.Current().Runtime.JudgeSampleRate
+	}, 100)
+	pipeline.OnResult(sampler.Observe)
+	slog.Info("✅ Judge initialized", "model", judge.Model(), "sample_rate", cfg.JudgeSampleRate)
+
+	docHandler := handler.NewDocumentHandler(db, embService, pipeline, quota)
+	openAIHandler := handler.NewOpenAIHandler(db, pipeline, quota)
+	feedbackHandler := handler.NewFeedbackHandler(feedbackStore)
+
+	// 의존성 health check (probe 결과는 10초간 캐시)
+	checker := health.NewChecker(10*time.Second, 3*time.Second)
+	probeClient := &http.Client{}
+	checker.Register("database", db.Ping)
+	checker.Register("embedding", health.OllamaModelProbe(probeClient, cfg.EmbeddingAPIURL, cfg.EmbeddingModel))
+	if cfg.LLMChatBackend == "openai" {
+		checker.Register("chat", health.OpenAIModelProbe(probeClient, cfg.LLMChatAPIURL, cfg.LLMChatAPIKey, cfg.LLMChatModel))
+	} else {
+		checker.Register("chat", health.OllamaModelProbe(probeClient, cfg.LLMChatAPIURL, cfg.LLMChatModel))
+	}
+	checker.Register("reranker", health.OllamaModelProbe(probeClient, cfg.RerankerAPIURL, cfg.RerankerModel))
+	healthHandler := handler.NewHealthHandler(checker)
+
+	// 백그라운드 작업은 rootCtx 취소 시 종료되고, 서버 종료 전에 모두 기다린다
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		checker.Run(rootCtx)
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		runtimeConfig.Watch(rootCtx, 5*time.Second)
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		sampler.Run(rootCtx)
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		audit.NewRetention(tenantStore, cfg.AuditRetentionDays, auditStore, feedbackStore).Run(rootCtx, time.Hour)
+	}()
 
 	// Gin 라우터
-	router := gin.Default()
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Tracing(), middleware.Logger(), gin.Recovery())
 
-	// API 라우트
-	api := router.Group("/api/v1")
+	// API 라우트 (API key 인증 필요)
+	api := router.Group("/api/v1", middleware.Authenticate(authenticator), middleware.Tenant(tenantStore), middleware.RateLimit(rateLimiter))
 	{
 		documents := api.Group("/documents")
 		{
-			documents.POST("", docHandler.InsertDocument)
-			documents.POST("/all", docHandler.InsertAllDocument)
-			documents.GET("/:id", docHandler.GetDocument)
-			documents.POST("/chat", docHandler.RagChatting)
+			documents.POST("", middleware.RequireScope(auth.ScopeIngest), docHandler.InsertDocument)
+			documents.POST("/all", middleware.RequireScope(auth.ScopeIngest), docHandler.InsertAllDocument)
+			documents.POST("/import", middleware.RequireScope(auth.ScopeIngest), docHandler.ImportDocuments)
+			documents.GET("/:id", middleware.RequireScope(auth.ScopeRead), docHandler.GetDocument)
+			documents.POST("/chat", middleware.RequireScope(auth.ScopeChat), middleware.LLMConcurrency(llmLimiter), docHandler.RagChatting)
+		}
+		// 답변 ID(또는 OpenAI completion ID)로 feedback 전송
+		api.POST("/answers/:id/feedback", middleware.RequireScope(auth.ScopeChat), feedbackHandler.SubmitFeedback)
+	}
+
+	// OpenAI 호환 API: 기존 OpenAI client에서 model "rag" 또는 "rag:<collection>"으로 사용
+	v1 := router.Group("/v1", middleware.Authenticate(authenticator), middleware.Tenant(tenantStore), middleware.RateLimit(rateLimiter), middleware.RequireScope(auth.ScopeChat))
+	{
+		v1.GET("/models", openAIHandler.ListModels)
+		v1.POST("/chat/completions", middleware.LLMConcurrency(llmLimiter), openAIHandler.ChatCompletions)
+	}
+
+	// Admin 라우트 (admin scope 필요)
+	adminHandler := handler.NewAdminHandler(runtimeConfig)
+	keyHandler := handler.NewKeyHandler(keyStore, tenantStore)
+	tenantHandler := handler.NewTenantHandler(tenantStore)
+	promptHandler := handler.NewPromptHandler(promptStore)
+	collectionHandler := handler.NewCollectionHandler(db)
+	qualityHandler := handler.NewQualityHandler(scoreStore)
+	auditHandler := handler.NewAuditHandler(auditStore)
+	admin := router.Group("/admin", middleware.Authenticate(authenticator), middleware.Tenant(tenantStore), middleware.RequireScope(auth.ScopeAdmin))
+	{
+		// 로그 설정은 모든 tenant에 영향을 주므로 platform admin만 변경 가능
+		admin.GET("/log-level", middleware.RequirePlatformAdmin(), adminHandler.GetLogLevel)
+		admin.PUT("/log-level", middleware.RequirePlatformAdmin(), adminHandler.SetLogLevel)
+		admin.GET("/config", middleware.RequirePlatformAdmin(), adminHandler.GetConfig)
+		admin.POST("/config/reload", middleware.RequirePlatformAdmin(), adminHandler.ReloadConfig)
+
+		admin.GET("/keys", keyHandler.ListKeys)
+		admin.POST("/keys", keyHandler.CreateKey)
+		admin.POST("/keys/:id/rotate", keyHandler.RotateKey)
+		admin.DELETE("/keys/:id", keyHandler.RevokeKey)
+
+		admin.GET("/prompts", promptHandler.ListPrompts)
+		admin.POST("/prompts", promptHandler.CreatePrompt)
+		admin.GET("/prompts/:name", promptHandler.GetPromptVersions)
+		admin.GET("/prompts/:name/versions/:version", promptHandler.GetPrompt)
+		admin.DELETE("/prompts/:name", promptHandler.DeletePrompt)
+		admin.GET("/prompt-bindings", promptHandler.ListPromptBindings)
+		admin.PUT("/prompt-bindings/:collection", promptHandler.BindPrompt)
+		admin.GET("/collections", collectionHandler.ListCollectionSettings)
+		admin.PUT("/collections/:collection", collectionHandler.SaveCollectionSettings)
+		admin.GET("/quality/trend", qualityHandler.GetQualityTrend)
+		admin.GET("/answers/:id/trace", feedbackHandler.GetTrace)
+		admin.GET("/feedback", feedbackHandler.ListFeedback)
+		admin.GET("/feedback/export", feedbackHandler.ExportFeedback)
+		admin.GET("/audit", auditHandler.SearchAudit)
+		admin.GET("/audit/export", auditHandler.ExportAudit)
+
+		tenants := admin.Group("/tenants", middleware.RequirePlatformAdmin())
+		{
+			tenants.GET("", tenantHandler.ListTenants)
+			tenants.GET("/:id", tenantHandler.GetTenant)
+			tenants.PUT("/:id", tenantHandler.SaveTenant)
 		}
 	}
 
@@ -70,11 +359,101 @@ func main() {
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// in-flight 요청의 context. drain timeout이 지나면 취소되어 upstream 호출도 중단된다
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	srv := &http.Server{
+		Addr:    cfg.ListenAddr,
+		Handler: router,
+		BaseContext: func(net.Listener) context.Context {
+			return requestCtx
+		},
+	}
 
 	// 서버 시작
-	log.Println("🚀 Server starting on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("🚀 Server starting", "addr", cfg.ListenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			slog.Error("Failed to start server", "error", err)
+			os.Exit(1)
+		}
+	case <-rootCtx.Done():
+	}
+	stop()
+
+	// load balancer가 빼 갈 수 있도록 먼저 /readyz를 not ready로 바꾸고 SHUTDOWN_DELAY만큼 요청을 계속 받는다
+	checker.StartDraining()
+	if cfg.ShutdownDelay > 0 {
+		slog.Info("🛑 Reporting not ready before shutdown", "delay", cfg.ShutdownDelay.String())
+		time.Sleep(cfg.ShutdownDelay)
 	}
 
+	// 새 요청은 받지 않고 in-flight 요청이 끝나기를 기다린다
+	slog.Info("🛑 Shutting down, draining in-flight requests", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Drain timeout exceeded, cancelling in-flight requests", "error", err)
+		cancelRequests()
+		srv.Close()
+	}
+
+	workers.Wait()
+
+	// 남은 span flush
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Warn("Failed to flush traces", "error", err)
+	}
+	slog.Info("✅ Server stopped")
+}
+
+// newChatService creates the chat service for the configured backend
+// (Ollama 또는 OpenAI 호환 서버: vLLM, llama.cpp server, LM Studio)
+func newChatService(cfg *config.Config) *chat.Service {
+	if cfg.LLMChatBackend == "openai" {
+		return chat.NewServiceWithBackend(chat.NewOpenAIModel(cfg.LLMChatAPIURL, cfg.LLMChatAPIKey), cfg.LLMChatModel)
+	}
+	return chat.NewService(cfg.LLMChatAPIURL, cfg.LLMChatModel)
+}
+
+// newJudgeService creates the chat service of the judge model.
+// JUDGE_API_URL이 비어 있으면 chat 서버를 함께 사용한다 (로컬 Ollama 모델로 채점 가능).
+func newJudgeService(cfg *config.Config) *chat.Service {
+	judgeCfg := *cfg
+	if cfg.JudgeAPIURL != "" {
+		judgeCfg.LLMChatAPIURL = cfg.JudgeAPIURL
+		judgeCfg.LLMChatAPIKey = cfg.JudgeAPIKey
+	}
+	if cfg.JudgeBackend != "" {
+		judgeCfg.LLMChatBackend = cfg.JudgeBackend
+	}
+	judgeCfg.LLMChatModel = judgeModel(cfg)
+	return newChatService(&judgeCfg)
+}
+
+// judgeModel returns JUDGE_MODEL, or the chat model when it is empty
+func judgeModel(cfg *config.Config) string {
+	if cfg.JudgeModel != "" {
+		return cfg.JudgeModel
+	}
+	return cfg.LLMChatModel
 }
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"example.com/hello/vector"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector exports pgxpool statistics
+type PoolCollector struct {
+	stat func() *pgxpool.Stat
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquireCount *prometheus.Desc
+	acquireWait  *prometheus.Desc
+}
+
+// NewPoolCollector creates a collector for the given pool stat function
+func NewPoolCollector(stat func() *pgxpool.Stat) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &PoolCollector{
+		stat:         stat,
+		acquired:     desc("acquired_conns", "Connections currently acquired."),
+		idle:         desc("idle_conns", "Connections currently idle."),
+		total:        desc("total_conns", "Total connections in the pool."),
+		max:          desc("max_conns", "Maximum pool size."),
+		acquireCount: desc("acquire_total", "Successful connection acquires."),
+		acquireWait:  desc("acquire_wait_seconds_total", "Total time spent waiting for a connection."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquireCount
+	ch <- c.acquireWait
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
+
+// DocumentCountCollector exports the number of documents per tenant and collection.
+// COUNT 쿼리 부하를 줄이기 위해 결과를 ttl 동안 캐시한다.
+type DocumentCountCollector struct {
+	count func(ctx context.Context) ([]vector.CollectionCount, error)
+	ttl   time.Duration
+	desc  *prometheus.Desc
+
+	mu        sync.Mutex
+	cached    []vector.CollectionCount
+	fetchedAt time.Time
+}
+
+// NewDocumentCountCollector creates a collector backed by count
+func NewDocumentCountCollector(count func(ctx context.Context) ([]vector.CollectionCount, error), ttl time.Duration) *DocumentCountCollector {
+	return &DocumentCountCollector{
+		count: count,
+		ttl:   ttl,
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "documents"),
+			"Number of stored documents per tenant and collection.", []string{"tenant", "collection"}, nil),
+	}
+}
+
+func (c *DocumentCountCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *DocumentCountCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fetchedAt.IsZero() || time.Since(c.fetchedAt) >= c.ttl {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		counts, err := c.count(ctx)
+		if err != nil {
+			slog.Warn("failed to collect document counts", "error", err)
+		} else {
+			c.cached = counts
+			c.fetchedAt = time.Now()
+		}
+	}
+
+	for _, count := range c.cached {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count.Count), count.TenantID, count.Collection)
+	}
+}
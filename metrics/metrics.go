@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "rag"
+
+// RAG pipeline stage 이름
+const (
+	StageRewrite = "rewrite"
+	StageHyDE    = "hyde"
+	StageEmbed   = "embed"
+	StageSearch  = "search"
+	StageRerank  = "rerank"
+	StageLLM     = "llm"
+)
+
+var (
+	// StageDuration tracks the latency of each RagChatting pipeline stage
+	StageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "stage_duration_seconds",
+		Help:      "Latency of each RAG pipeline stage.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"stage"})
+
+	// UpstreamErrors counts failed calls to upstream services
+	UpstreamErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_errors_total",
+		Help:      "Errors returned by upstream services, by service and reason.",
+	}, []string{"service", "reason"})
+
+	// RerankDocuments counts documents kept or dropped by the rerank threshold
+	RerankDocuments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rerank_documents_total",
+		Help:      "Documents evaluated by the reranker, by outcome (kept, dropped).",
+	}, []string{"outcome"})
+
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+
+	rerankKept    atomic.Uint64
+	rerankDropped atomic.Uint64
+)
+
+func init() {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "embedding_cache_hit_ratio",
+		Help:      "Ratio of query embeddings served from the cache.",
+	}, func() float64 {
+		return ratio(cacheHits.Load(), cacheMisses.Load())
+	})
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rerank_drop_ratio",
+		Help:      "Ratio of documents dropped by the rerank score threshold.",
+	}, func() float64 {
+		return ratio(rerankDropped.Load(), rerankKept.Load())
+	})
+}
+
+// ObserveStage records the duration of a pipeline stage started at start
+func ObserveStage(stage string, start time.Time) {
+	StageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+}
+
+// RecordUpstreamError increments the upstream error counter
+func RecordUpstreamError(service, reason string) {
+	UpstreamErrors.WithLabelValues(service, reason).Inc()
+}
+
+// TransportReason classifies an error returned by http.Client.Do
+func TransportReason(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "connection"
+	}
+}
+
+// RecordCache records an embedding cache lookup
+func RecordCache(hit bool) {
+	if hit {
+		cacheHits.Add(1)
+	} else {
+		cacheMisses.Add(1)
+	}
+}
+
+// RecordRerank records how many documents the reranker kept and dropped
+func RecordRerank(kept, dropped int) {
+	RerankDocuments.WithLabelValues("kept").Add(float64(kept))
+	RerankDocuments.WithLabelValues("dropped").Add(float64(dropped))
+	rerankKept.Add(uint64(kept))
+	rerankDropped.Add(uint64(dropped))
+}
+
+func ratio(part, rest uint64) float64 {
+	if part+rest == 0 {
+		return 0
+	}
+	return float64(part) / float64(part+rest)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"example.com/hello/auth"
+	"example.com/hello/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticator resolves a credential into a principal
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*auth.Principal, error)
+}
+
+// Authenticate requires a valid API key or JWT in the Authorization (Bearer) or X-API-Key header
+func Authenticate(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential := credentialFromRequest(c.Request)
+		if credential == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing credentials"})
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), credential)
+		if err != nil {
+			logger := logging.FromContext(c.Request.Context())
+			if errors.Is(err, auth.ErrInvalidKey) || errors.Is(err, auth.ErrInvalidToken) {
+				logger.Info("authentication rejected", "error", err)
+			} else {
+				logger.Error("authentication failed", "error", err)
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose principal lacks scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing credentials"})
+			return
+		}
+		if !principal.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing scope: " + scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+func credentialFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"time"
+
+	"example.com/hello/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// Logger writes one structured access log line per request
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logger := logging.FromContext(c.Request.Context())
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.FullPath(),
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+
+		switch {
+		case c.Writer.Status() >= 500:
+			logger.Error("request completed", attrs...)
+		case c.Writer.Status() >= 400:
+			logger.Warn("request completed", attrs...)
+		default:
+			logger.Info("request completed", attrs...)
+		}
+	}
+}
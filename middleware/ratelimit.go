@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/hello/auth"
+	"example.com/hello/logging"
+	"example.com/hello/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit applies a per-client token bucket (API key / user, or IP for anonymous requests)
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, retryAfter := limiter.Allow(ClientKey(c)); !ok {
+			TooManyRequests(c, retryAfter, "rate limit exceeded")
+			return
+		}
+		c.Next()
+	}
+}
+
+// LLMConcurrency limits concurrent requests that call the LLM, queueing the excess
+func LLMConcurrency(limiter *ratelimit.ConcurrencyLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, err := limiter.Acquire(c.Request.Context())
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Warn("LLM concurrency limit reached",
+				"error", err, "in_flight", limiter.InFlight(), "waiting", limiter.Waiting())
+			if errors.Is(err, ratelimit.ErrQueueFull) || errors.Is(err, ratelimit.ErrQueueTimeout) {
+				TooManyRequests(c, 5*time.Second, err.Error())
+			} else {
+				c.Abort()
+			}
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+// ClientKey identifies the caller for rate limiting and quotas
+func ClientKey(c *gin.Context) string {
+	if p, ok := auth.FromContext(c.Request.Context()); ok {
+		return p.ID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// TooManyRequests writes a 429 response with a Retry-After header
+func TooManyRequests(c *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"example.com/hello/auth"
+	"example.com/hello/logging"
+	"example.com/hello/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+const TenantHeader = "X-Tenant-ID"
+
+// Tenant resolves the tenant of the authenticated principal and stores it in the request context.
+// platform admin은 X-Tenant-ID 헤더로 다른 tenant를 지정할 수 있다.
+func Tenant(store *tenant.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing credentials"})
+			return
+		}
+
+		tenantID := principal.TenantID
+		if header := c.GetHeader(TenantHeader); header != "" && principal.PlatformAdmin {
+			tenantID = header
+		}
+
+		t, err := store.Get(c.Request.Context(), tenantID)
+		if errors.Is(err, tenant.ErrNotFound) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "unknown tenant " + tenantID})
+			return
+		}
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("failed to resolve tenant", "tenant", tenantID, "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve tenant"})
+			return
+		}
+
+		ctx := tenant.WithTenant(c.Request.Context(), t)
+		c.Request = c.Request.WithContext(logging.WithTenantID(ctx, t.ID))
+		c.Next()
+	}
+}
+
+// RequirePlatformAdmin rejects requests that are not made with the bootstrap admin key
+func RequirePlatformAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok || !principal.PlatformAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "platform admin required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,303 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store stores versioned prompt templates and per-collection bindings in Postgres
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+// Binding selects the template used for one kind of prompt in a collection
+type Binding struct {
+	Collection string `json:"collection"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// NewStore creates a new prompt template store
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+// Migrate creates the prompt tables
+func (s *Store) Migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS prompt_templates (
+            tenant_id  TEXT NOT NULL,
+            name       TEXT NOT NULL,
+            version    INTEGER NOT NULL,
+            kind       TEXT NOT NULL,
+            body       TEXT NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+            PRIMARY KEY (tenant_id, name, version)
+        )`,
+		`CREATE TABLE IF NOT EXISTS prompt_bindings (
+            tenant_id  TEXT NOT NULL,
+            collection TEXT NOT NULL,
+            kind       TEXT NOT NULL,
+            name       TEXT NOT NULL,
+            PRIMARY KEY (tenant_id, collection, kind)
+        )`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate prompt templates: %w", err)
+		}
+	}
+	return nil
+}
+
+// Create stores body as the next version of the named template.
+// 기존 버전은 수정하지 않으므로 과거 답변이 어떤 프롬프트로 만들어졌는지 재현할 수 있다.
+func (s *Store) Create(ctx context.Context, tenantID, name, kind, body string) (*Template, error) {
+	if _, ok := Builtin(name); ok {
+		return nil, errBuiltinRefused
+	}
+	t := &Template{TenantID: tenantID, Name: name, Kind: kind, Body: body}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// 같은 이름을 동시에 만들면 MAX(version)+1이 겹치므로 transaction이 끝날 때까지 이름 단위로 직렬화
+	// (row lock은 첫 버전을 만들 때 잠글 row가 없다)
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))`, tenantID, name); err != nil {
+		return nil, fmt.Errorf("failed to lock prompt template: %w", err)
+	}
+
+	// 같은 이름의 템플릿은 kind를 바꿀 수 없다
+	var existingKind string
+	err = tx.QueryRow(ctx, `
+        SELECT kind FROM prompt_templates WHERE tenant_id = $1 AND name = $2 LIMIT 1
+    `, tenantID, name).Scan(&existingKind)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get prompt template: %w", err)
+	}
+	if existingKind != "" && existingKind != kind {
+		return nil, fmt.Errorf("template %s is a %s template", name, existingKind)
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO prompt_templates (tenant_id, name, version, kind, body)
+        SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4
+        FROM prompt_templates
+        WHERE tenant_id = $1 AND name = $2
+        RETURNING version, created_at
+    `, tenantID, name, kind, body).Scan(&t.Version, &t.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prompt template: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return t, nil
+}
+
+// Get returns a version of a template (version 0 = 최신 버전)
+func (s *Store) Get(ctx context.Context, tenantID, name string, version int) (*Template, error) {
+	if t, ok := Builtin(name); ok {
+		if version != 0 {
+			return nil, ErrNotFound
+		}
+		return t, nil
+	}
+
+	var t Template
+	err := s.pool.QueryRow(ctx, `
+        SELECT tenant_id, name, version, kind, body, created_at
+        FROM prompt_templates
+        WHERE tenant_id = $1 AND name = $2 AND ($3 = 0 OR version = $3)
+        ORDER BY version DESC
+        LIMIT 1
+    `, tenantID, name, version).Scan(&t.TenantID, &t.Name, &t.Version, &t.Kind, &t.Body, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt template: %w", err)
+	}
+	return &t, nil
+}
+
+// List returns the latest version of every template of tenantID, including built-ins
+func (s *Store) List(ctx context.Context, tenantID string) ([]Template, error) {
+	templates := []Template{*builtins[DefaultChat], *builtins[DefaultRerank], *builtins[DefaultRewrite], *builtins[DefaultHyDE],
+		*builtins[DefaultJudge]}
+
+	rows, err := s.pool.Query(ctx, `
+        SELECT DISTINCT ON (name) tenant_id, name, version, kind, body, created_at
+        FROM prompt_templates
+        WHERE tenant_id = $1
+        ORDER BY name, version DESC
+    `, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Template
+		if err := rows.Scan(&t.TenantID, &t.Name, &t.Version, &t.Kind, &t.Body, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// Versions returns every version of a template, newest first
+func (s *Store) Versions(ctx context.Context, tenantID, name string) ([]Template, error) {
+	if t, ok := Builtin(name); ok {
+		return []Template{*t}, nil
+	}
+
+	rows, err := s.pool.Query(ctx, `
+        SELECT tenant_id, name, version, kind, body, created_at
+        FROM prompt_templates
+        WHERE tenant_id = $1 AND name = $2
+        ORDER BY version DESC
+    `, tenantID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt template versions: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []Template
+	for rows.Next() {
+		var t Template
+		if err := rows.Scan(&t.TenantID, &t.Name, &t.Version, &t.Kind, &t.Body, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, ErrNotFound
+	}
+	return templates, nil
+}
+
+// Delete removes every version of a template and its collection bindings
+func (s *Store) Delete(ctx context.Context, tenantID, name string) error {
+	if _, ok := Builtin(name); ok {
+		return errBuiltinRefused
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, "DELETE FROM prompt_templates WHERE tenant_id = $1 AND name = $2", tenantID, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM prompt_bindings WHERE tenant_id = $1 AND name = $2", tenantID, name); err != nil {
+		return fmt.Errorf("failed to delete prompt bindings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Bind selects the template used for kind prompts in collection (name "" = 바인딩 해제)
+func (s *Store) Bind(ctx context.Context, tenantID string, b Binding) error {
+	if b.Name == "" {
+		_, err := s.pool.Exec(ctx, "DELETE FROM prompt_bindings WHERE tenant_id = $1 AND collection = $2 AND kind = $3",
+			tenantID, b.Collection, b.Kind)
+		if err != nil {
+			return fmt.Errorf("failed to delete prompt binding: %w", err)
+		}
+		return nil
+	}
+
+	t, err := s.Get(ctx, tenantID, b.Name, 0)
+	if err != nil {
+		return err
+	}
+	if t.Kind != b.Kind {
+		return fmt.Errorf("template %s is a %s template", b.Name, t.Kind)
+	}
+
+	_, err = s.pool.Exec(ctx, `
+        INSERT INTO prompt_bindings (tenant_id, collection, kind, name)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (tenant_id, collection, kind) DO UPDATE SET name = EXCLUDED.name
+    `, tenantID, b.Collection, b.Kind, b.Name)
+	if err != nil {
+		return fmt.Errorf("failed to save prompt binding: %w", err)
+	}
+	return nil
+}
+
+// Bindings returns the collection bindings of tenantID
+func (s *Store) Bindings(ctx context.Context, tenantID string) ([]Binding, error) {
+	rows, err := s.pool.Query(ctx, `
+        SELECT collection, kind, name FROM prompt_bindings WHERE tenant_id = $1 ORDER BY collection, kind
+    `, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt bindings: %w", err)
+	}
+	defer rows.Close()
+
+	var bindings []Binding
+	for rows.Next() {
+		var b Binding
+		if err := rows.Scan(&b.Collection, &b.Kind, &b.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, rows.Err()
+}
+
+// Resolve picks the template for a request: 요청에서 지정한 템플릿 → collection 바인딩 → 기본 템플릿 순
+func (s *Store) Resolve(ctx context.Context, tenantID, collection, kind, requested string) (*Template, error) {
+	if requested != "" {
+		name, version, err := ParseRef(requested)
+		if err != nil {
+			return nil, err
+		}
+		t, err := s.Get(ctx, tenantID, name, version)
+		if err != nil {
+			return nil, fmt.Errorf("prompt template %s: %w", requested, err)
+		}
+		if t.Kind != kind {
+			return nil, fmt.Errorf("%w: %s is a %s template, not %s", ErrInvalidRef, requested, t.Kind, kind)
+		}
+		return t, nil
+	}
+
+	var name string
+	err := s.pool.QueryRow(ctx, `
+        SELECT name FROM prompt_bindings WHERE tenant_id = $1 AND collection = $2 AND kind = $3
+    `, tenantID, collection, kind).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultFor(kind), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt binding: %w", err)
+	}
+	return s.Get(ctx, tenantID, name, 0)
+}
@@ -0,0 +1,60 @@
+package prompt
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/hello/internal/dbtest"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testStore connects to TEST_DATABASE_URL, 없으면 test를 건너뛴다
+func testStore(t *testing.T) (*Store, *pgxpool.Pool) {
+	t.Helper()
+	pool := dbtest.Pool(t)
+	store := NewStore(pool)
+	dbtest.Migrate(t, store)
+	return store, pool
+}
+
+// TestCreateConcurrent creates versions of a new template from several goroutines at once
+func TestCreateConcurrent(t *testing.T) {
+	store, pool := testStore(t)
+	tenantID := "test-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), "DELETE FROM prompt_templates WHERE tenant_id = $1", tenantID)
+	})
+
+	const n = 8
+	var wg sync.WaitGroup
+	versions := make([]int, n)
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tmpl, err := store.Create(context.Background(), tenantID, "concurrent", KindChat, "version "+strconv.Itoa(i)+" {{.Question}}")
+			errs[i] = err
+			if err == nil {
+				versions[i] = tmpl.Version
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Create %d: %v", i, err)
+		}
+	}
+	slices.Sort(versions)
+	for i, v := range versions {
+		if v != i+1 {
+			t.Fatalf("versions = %v, want 1..%d", versions, n)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Template kinds
+const (
+	KindChat    = "chat"
+	KindRerank  = "rerank"
+	KindRewrite = "rewrite"
+	KindHyDE    = "hyde"
+	KindJudge   = "judge"
+)
+
+// Built-in template names (version 0, DB에 없어도 항상 사용 가능)
+const (
+	DefaultChat    = "default-chat"
+	DefaultRerank  = "default-rerank"
+	DefaultRewrite = "default-rewrite"
+	DefaultHyDE    = "default-hyde"
+	DefaultJudge   = "default-judge"
+)
+
+var (
+	ErrNotFound       = errors.New("prompt template not found")
+	ErrInvalidRef     = errors.New("invalid prompt template reference")
+	validNameRegex    = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+	errBuiltinRefused = errors.New("built-in templates cannot be modified")
+)
+
+var kinds = []string{KindChat, KindRerank, KindRewrite, KindHyDE, KindJudge}
+
+// Template is one immutable version of a named prompt template
+type Template struct {
+	TenantID  string    `json:"tenant_id,omitempty"`
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	Kind      string    `json:"kind"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Ref returns "name@version", the identifier recorded with answers
+func (t *Template) Ref() string {
+	return t.Name + "@" + strconv.Itoa(t.Version)
+}
+
+// Document is a retrieved or reranked document passed to a template
+type Document struct {
+	Index      int
+	Content    string
+	Score      float64 // rerank 점수
+	Distance   float64 // vector 거리
+	Similarity float64 // 1 - Distance
+}
+
+// Data is the set of variables available to templates
+type Data struct {
+	Question     string
+	Documents    []Document
+	Language     string
+	Date         string
+	SystemPrompt string // tenant 또는 runtime 설정의 system prompt
+	Count        int    // rewrite: 생성할 query 수
+	Answer       string // judge: 평가할 답변
+}
+
+// NewData fills Date with today's date
+func NewData(question string, documents []Document, language, systemPrompt string) Data {
+	return Data{
+		Question:     question,
+		Documents:    documents,
+		Language:     language,
+		Date:         time.Now().Format(time.DateOnly),
+		SystemPrompt: systemPrompt,
+	}
+}
+
+var funcs = template.FuncMap{
+	// truncate는 문자(rune) 단위로 자른다 (byte로 자르면 한글이 깨진다)
+	"truncate": func(maxChars int, s string) string {
+		if maxChars < 0 {
+			return ""
+		}
+		for i := range s {
+			if maxChars == 0 {
+				return s[:i]
+			}
+			maxChars--
+		}
+		return s
+	},
+	"add": func(a, b int) int { return a + b },
+}
+
+// Render executes the template with data
+func (t *Template) Render(data Data) (string, error) {
+	tmpl, err := parse(t.Name, t.Body)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", t.Ref(), err)
+	}
+	return sb.String(), nil
+}
+
+// Validate checks that the template parses and renders with sample data
+func (t *Template) Validate() error {
+	if !validNameRegex.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name %q", t.Name)
+	}
+	if !slices.Contains(kinds, t.Kind) {
+		return fmt.Errorf("invalid template kind %q (expected one of %s)", t.Kind, strings.Join(kinds, ", "))
+	}
+	sample := NewData("sample question", []Document{{Index: 0, Content: "sample document", Score: 0.9, Distance: 0.1, Similarity: 0.9}}, "ko", "")
+	sample.Count = 3
+	_, err := t.Render(sample)
+	return err
+}
+
+func parse(name, body string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// ParseRef splits "name" or "name@version" (version 0 = 최신 버전)
+func ParseRef(ref string) (name string, version int, err error) {
+	name, v, ok := strings.Cut(ref, "@")
+	if !ok {
+		return name, 0, nil
+	}
+	version, err = strconv.Atoi(v)
+	if err != nil || version < 0 {
+		return "", 0, fmt.Errorf("%w %q: version must be a non-negative number", ErrInvalidRef, ref)
+	}
+	return name, version, nil
+}
+
+// builtins are the prompts that used to be hard-coded in chat.Service and reranker.buildPrompt
+var builtins = map[string]*Template{
+	DefaultChat: {
+		Name: DefaultChat,
+		Kind: KindChat,
+		Body: `{{if .SystemPrompt}}{{.SystemPrompt}}
+
+{{else}}당신은 주어진 모든 참고 문서들을 종합하여 질문에 친절하게 안내하는 챗봇입니다.
+
+중요 규칙:
+1. 모든 참고 문서를 종합해 자연스러운 한두 문장으로 답변하세요.
+2. 필요한 정보만 간결하고 부드러운 말투로 안내하세요.
+
+3. 정보가 없으면 정중히 없다고 답하세요.
+
+{{end}}{{with .Language}}답변 언어: {{.}}
+
+{{end}}{{if .Documents}}=== 참고 문서 (총 {{len .Documents}}개) ===
+{{range $i, $d := .Documents}}
+[문서 {{add $i 1}}] (관련도: {{printf "%.2f" $d.Score}})
+{{$d.Content}}
+{{end}}
+=== 모든 문서를 검토한 후 합해서 답변하세요 ===
+{{else}}참고할 문서가 없습니다.
+{{end}}`,
+	},
+	DefaultRerank: {
+		Name: DefaultRerank,
+		Kind: KindRerank,
+		Body: `You are a reranking system.
+Score each document for relevance to the query.
+
+RULES:
+OUTPUT ONLY VALID JSON. NO EXTRA TEXT.
+
+- No markdown, no extra text
+- Exactly {{len .Documents}} results
+- Score range: 0.0 to 1.0
+- Consider both semantic relevance AND vector distance
+- Start with { and end with }
+
+Query:
+{{.Question}}
+
+Documents:
+{{range .Documents}}D{{.Index}} (distance: {{printf "%.3f" .Similarity}}): {{truncate 200 .Content}}
+{{end}}
+Output JSON format:
+{"results":[{{range $i, $d := .Documents}}{{if $i}},{{end}}{"index":{{$d.Index}},"score":0.0}{{end}}]}`,
+	},
+	DefaultRewrite: {
+		Name: DefaultRewrite,
+		Kind: KindRewrite,
+		Body: `You rewrite search queries for a document retrieval system.
+Write {{.Count}} different search queries for the question below.
+Use paraphrases, or split it into sub-questions if it asks several things.
+Keep the language of the question.
+
+RULES:
+- One query per line
+- No numbering, no explanations, no extra text
+
+Question:
+{{.Question}}`,
+	},
+	DefaultJudge: {
+		Name: DefaultJudge,
+		Kind: KindJudge,
+		Body: `You are grading an answer produced by a retrieval-augmented chatbot.
+
+Question:
+{{.Question}}
+
+Documents given to the chatbot:
+{{range .Documents}}[{{.Index}}] {{truncate 1000 .Content}}
+{{else}}(none)
+{{end}}
+Answer:
+{{.Answer}}
+
+Score the answer:
+- faithfulness: fraction of the claims in the answer that are supported by the documents (0.0 to 1.0).
+  An answer that correctly says the information is not available is faithful.
+- answer_relevance: how directly and completely the answer addresses the question (0.0 to 1.0)
+- context_relevance: for each document in order, 1 if it is useful to answer the question, otherwise 0
+
+OUTPUT ONLY VALID JSON. NO EXTRA TEXT.
+{"faithfulness":0.0,"answer_relevance":0.0,"context_relevance":[{{range $i, $d := .Documents}}{{if $i}},{{end}}0{{end}}],"reason":"one sentence"}`,
+	},
+	DefaultHyDE: {
+		Name: DefaultHyDE,
+		Kind: KindHyDE,
+		Body: `Write a short passage (3-5 sentences) that answers the question below,
+as it would appear in an internal document or FAQ.
+Write in the language of the question. If you are unsure, write a plausible answer anyway.
+Output only the passage.
+
+Question:
+{{.Question}}`,
+	},
+}
+
+// Builtin returns the built-in template name, if any
+func Builtin(name string) (*Template, bool) {
+	t, ok := builtins[name]
+	return t, ok
+}
+
+// DefaultFor returns the built-in template of kind
+func DefaultFor(kind string) *Template {
+	switch kind {
+	case KindRerank:
+		return builtins[DefaultRerank]
+	case KindRewrite:
+		return builtins[DefaultRewrite]
+	case KindHyDE:
+		return builtins[DefaultHyDE]
+	case KindJudge:
+		return builtins[DefaultJudge]
+	}
+	return builtins[DefaultChat]
+}
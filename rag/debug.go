@@ -0,0 +1,124 @@
+package rag
+
+import (
+	"fmt"
+	"time"
+
+	"example.com/hello/chat"
+	"example.com/hello/reranker"
+	"example.com/hello/vector"
+)
+
+// Debug is the step-by-step trace of one request (debug: true, admin 전용)
+type Debug struct {
+	ConfigVersion int    `json:"config_version"`
+	Mode          string `json:"mode"`
+	HyDEDocument  string `json:"hyde_document,omitempty"`
+	// Searches는 query별 embedding/검색 결과 (fusion, MMR 이전)
+	Searches []SearchDebug `json:"searches"`
+	// MMR을 사용했으면 MMR 이전 후보
+	Candidates   []Hit                     `json:"mmr_candidates,omitempty"`
+	RerankInput  []Hit                     `json:"rerank_input"`
+	Rerank       reranker.Trace            `json:"rerank"`
+	RerankOutput []reranker.RankedDocument `json:"rerank_output"`
+	Dropped      []Dropped                 `json:"dropped"`
+	Chat         chat.Trace                `json:"chat"`
+}
+
+// SearchDebug is the embedding and search of one query
+type SearchDebug struct {
+	Query       string  `json:"query"`
+	EmbeddingMS float64 `json:"embedding_ms"`
+	SearchMS    float64 `json:"search_ms"`
+	Hits        []Hit   `json:"hits"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Hit is a search result with its cosine distance
+type Hit struct {
+	DocumentID int     `json:"document_id"`
+	Distance   float64 `json:"distance"`
+	Content    string  `json:"content"`
+}
+
+// Dropped is a document found by search that did not reach the chat model
+type Dropped struct {
+	DocumentID int    `json:"document_id"`
+	Stage      string `json:"stage"`
+	Reason     string `json:"reason"`
+}
+
+func hits(docs []vector.Document) []Hit {
+	list := make([]Hit, len(docs))
+	for i, doc := range docs {
+		list[i] = Hit{DocumentID: doc.ID, Distance: doc.Distance, Content: doc.Content}
+	}
+	return list
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// explainDropped lists every searched document that was not given to the chat model, with the stage that dropped it
+func (d *Debug) explainDropped(r *Retrieval, limit int) {
+	dropped := map[int]bool{}
+	drop := func(id int, stage, reason string) {
+		if !dropped[id] {
+			dropped[id] = true
+			d.Dropped = append(d.Dropped, Dropped{DocumentID: id, Stage: stage, Reason: reason})
+		}
+	}
+
+	// 여러 query 결과를 합칠 때 상위 limit개 밖으로 밀린 문서
+	fused := d.Candidates
+	if fused == nil {
+		fused = d.RerankInput
+	}
+	inFused := map[int]bool{}
+	for _, hit := range fused {
+		inFused[hit.DocumentID] = true
+	}
+	for _, search := range d.Searches {
+		for _, hit := range search.Hits {
+			if !inFused[hit.DocumentID] {
+				drop(hit.DocumentID, "fusion", fmt.Sprintf("not in the top %d after fusing %d queries", limit, len(d.Searches)))
+			}
+		}
+	}
+
+	// MMR에서 선택되지 않은 후보
+	selected := map[int]bool{}
+	for _, hit := range d.RerankInput {
+		selected[hit.DocumentID] = true
+	}
+	for _, hit := range d.Candidates {
+		if !selected[hit.DocumentID] {
+			drop(hit.DocumentID, "mmr", fmt.Sprintf("not selected by MMR (lambda %.2f)", r.MMRLambda))
+		}
+	}
+
+	// rerank: 입력 수 제한, threshold 미달, 점수 누락
+	kept := map[int]bool{}
+	for _, doc := range d.RerankOutput {
+		kept[doc.DocumentID] = true
+	}
+	scores := map[int]float64{}
+	for _, doc := range d.Rerank.Scores {
+		scores[doc.DocumentID] = doc.Score
+	}
+	for i, hit := range d.RerankInput {
+		if kept[hit.DocumentID] {
+			continue
+		}
+		score, scored := scores[hit.DocumentID]
+		switch {
+		case i >= d.Rerank.Input:
+			drop(hit.DocumentID, "rerank", fmt.Sprintf("not sent to the reranker (limit %d)", d.Rerank.Limit))
+		case scored:
+			drop(hit.DocumentID, "rerank", fmt.Sprintf("score %.3f is not above threshold %.2f", score, d.Rerank.Threshold))
+		default:
+			drop(hit.DocumentID, "rerank", "the reranker returned no score")
+		}
+	}
+}
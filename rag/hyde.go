@@ -0,0 +1,68 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"example.com/hello/chat"
+	"example.com/hello/prompt"
+)
+
+// Retrieval modes
+const (
+	// ModeVector searches with the embedding of the question
+	ModeVector = "vector"
+	// ModeHyDE searches with the embedding of a hypothetical answer passage written by the chat model
+	ModeHyDE = "hyde"
+)
+
+// hypotheticalDocument asks the chat model for a passage that would answer the question
+func (p *Pipeline) hypotheticalDocument(ctx context.Context, r *Retrieval) (string, chat.Usage, error) {
+	req := r.Request
+	tmpl, err := p.prompts.Resolve(ctx, req.Tenant.ID, req.Collection, prompt.KindHyDE, "")
+	if err != nil {
+		return "", chat.Usage{}, err
+	}
+	rendered, err := tmpl.Render(prompt.NewData(req.Question, nil, req.Language, ""))
+	if err != nil {
+		return "", chat.Usage{}, err
+	}
+
+	content, usage, err := p.chat.Complete(ctx, []chat.Message{{Role: "user", Content: rendered}}, chat.Options{
+		Model: firstNonEmpty(req.Tenant.ChatModel, r.Snapshot.Runtime.LLMChatModel),
+	})
+	if err != nil {
+		return "", chat.Usage{}, err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", usage, fmt.Errorf("chat model returned an empty hypothetical document")
+	}
+	return content, usage, nil
+}
+
+// hydeVector embeds the hypothetical document, optionally averaged with the question embedding
+func (p *Pipeline) hydeVector(ctx context.Context, r *Retrieval) ([]float32, error) {
+	docVector, err := p.emb.GenerateEmbedding(ctx, r.HyDEDocument)
+	if err != nil {
+		return nil, err
+	}
+	if !r.HyDEAverage {
+		return docVector, nil
+	}
+
+	queryVector, err := p.emb.GenerateEmbedding(ctx, r.Request.Question)
+	if err != nil {
+		return nil, err
+	}
+	if len(queryVector) != len(docVector) {
+		return nil, fmt.Errorf("embedding dimensions differ: %d != %d", len(queryVector), len(docVector))
+	}
+	// cosine 거리는 크기와 무관하므로 단순 평균으로 충분하다
+	averaged := make([]float32, len(docVector))
+	for i := range docVector {
+		averaged[i] = (docVector[i] + queryVector[i]) / 2
+	}
+	return averaged, nil
+}
@@ -0,0 +1,65 @@
+package rag
+
+import (
+	"math"
+
+	"example.com/hello/vector"
+)
+
+// mmr selects k documents by maximal marginal relevance:
+// lambda * 질문과의 유사도 - (1 - lambda) * 이미 고른 문서와의 최대 유사도.
+// 후보는 embedding을 포함해야 하며, 질문과의 유사도는 1 - Distance를 사용한다.
+func mmr(candidates []vector.Document, k int, lambda float64) []vector.Document {
+	if len(candidates) <= k {
+		return candidates
+	}
+
+	selected := make([]vector.Document, 0, k)
+	used := make([]bool, len(candidates))
+	// maxSim[i]는 후보 i와 이미 선택된 문서들 사이의 최대 cosine 유사도
+	maxSim := make([]float64, len(candidates))
+	for i := range maxSim {
+		maxSim[i] = -1
+	}
+
+	for len(selected) < k {
+		best, bestScore := -1, math.Inf(-1)
+		for i, doc := range candidates {
+			if used[i] {
+				continue
+			}
+			score := lambda * (1 - doc.Distance)
+			if len(selected) > 0 {
+				score -= (1 - lambda) * maxSim[i]
+			}
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		used[best] = true
+		selected = append(selected, candidates[best])
+		for i, doc := range candidates {
+			if !used[i] {
+				maxSim[i] = math.Max(maxSim[i], cosine(doc.Embedding, candidates[best].Embedding))
+			}
+		}
+	}
+	return selected
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,358 @@
+package rag
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/hello/chat"
+	"example.com/hello/config"
+	"example.com/hello/embedding"
+	"example.com/hello/logging"
+	"example.com/hello/metrics"
+	"example.com/hello/prompt"
+	"example.com/hello/reranker"
+	"example.com/hello/tenant"
+	"example.com/hello/vector"
+)
+
+// Pipeline runs retrieve → rerank → chat for a question.
+// /api/v1/documents/chat 와 OpenAI 호환 /v1/chat/completions 가 같은 pipeline을 사용한다.
+type Pipeline struct {
+	db       *vector.VectorDB
+	emb      *embedding.Service
+	reranker *reranker.Service
+	chat     *chat.Service
+	runtime  *config.Manager
+	prompts  *prompt.Store
+	// 답변 생성 후 호출 (judge sampling 등). 서버 시작 전에만 등록한다
+	observers []func(context.Context, *Retrieval, *Result)
+}
+
+// NewPipeline creates a new RAG pipeline
+func NewPipeline(db *vector.VectorDB, emb *embedding.Service, rerankerService *reranker.Service, chatService *chat.Service, runtime *config.Manager, prompts *prompt.Store) *Pipeline {
+	return &Pipeline{
+		db:       db,
+		emb:      emb,
+		reranker: rerankerService,
+		chat:     chatService,
+		runtime:  runtime,
+		prompts:  prompts,
+	}
+}
+
+// OnResult registers fn to be called after every generated answer.
+// fn은 요청 goroutine에서 호출되므로 오래 걸리는 작업은 직접 background로 넘겨야 한다.
+func (p *Pipeline) OnResult(fn func(context.Context, *Retrieval, *Result)) {
+	p.observers = append(p.observers, fn)
+}
+
+// Request is one question asked by a caller
+type Request struct {
+	Question   string
+	Tenant     *tenant.Tenant
+	Collection string
+	Access     vector.Access
+	// "name" 또는 "name@version". 비어 있으면 collection 바인딩 또는 기본 템플릿
+	PromptTemplate string
+	RerankTemplate string
+	Language       string
+	Generation     chat.GenerateOptions
+	// 추가로 생성할 검색 query 수. nil이면 runtime 설정(QUERY_REWRITES) 사용
+	QueryRewrites *int
+	// RetrievalMode (vector, hyde). 비어 있으면 collection 설정, 없으면 vector
+	RetrievalMode string
+	// HyDEAverage가 nil이면 collection 설정 사용
+	HyDEAverage *bool
+	// MMR, MMRLambda가 nil이면 runtime 설정(MMR_ENABLED, MMR_LAMBDA) 사용
+	MMR       *bool
+	MMRLambda *float64
+	// Debug이면 단계별 trace를 Result.Debug로 반환한다 (handler에서 admin만 허용)
+	Debug bool
+}
+
+// Retrieval is the state after retrieval and rerank, ready for generation
+type Retrieval struct {
+	// ID는 답변 ID (feedback, trace 조회용)
+	ID             string
+	StartedAt      time.Time
+	Request        Request
+	Snapshot       *config.Snapshot
+	ChatTemplate   *prompt.Template
+	RerankTemplate *prompt.Template
+	// Mode는 실제 사용한 검색 방식, HyDEDocument는 hyde 모드에서 생성한 가상 답변 문서
+	Mode         string
+	HyDEDocument string
+	HyDEAverage  bool
+	// Queries는 검색에 사용한 query (원래 질문 + rewrite 결과)
+	Queries []string
+	// MMR을 사용했으면 Candidates는 MMR 이전 후보 수
+	MMR        bool
+	MMRLambda  float64
+	Candidates int
+	Similar    []vector.Document
+	Sources    []reranker.RankedDocument
+	// Usage는 query rewrite, HyDE, rerank에 사용한 토큰
+	Usage chat.Usage
+	// Debug는 Request.Debug일 때만 채워진다
+	Debug *Debug
+}
+
+// Result is a generated answer with the documents it was based on
+type Result struct {
+	ID      string                    `json:"answer_id"`
+	Answer  string                    `json:"answer"`
+	Usage   chat.Usage                `json:"usage"`
+	Sources []reranker.RankedDocument `json:"sources"`
+	Prompt  string                    `json:"prompt"`
+	Model   string                    `json:"model"`
+	Debug   *Debug                    `json:"debug,omitempty"`
+	// Incomplete이면 생성이 Error로 중단되어 Answer는 그때까지 만든 일부
+	Incomplete bool   `json:"incomplete,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Retrieve resolves prompt templates, embeds the question, searches and reranks.
+// 요청 시작 시점의 runtime 설정을 끝까지 사용 (reload 중에도 일관성 유지)
+func (p *Pipeline) Retrieve(ctx context.Context, req Request) (*Retrieval, error) {
+	if req.Tenant == nil {
+		req.Tenant = &tenant.Tenant{ID: tenant.DefaultID}
+	}
+	if req.Collection == "" {
+		req.Collection = vector.DefaultCollection
+	}
+	r := &Retrieval{ID: newAnswerID(), StartedAt: time.Now(), Request: req, Snapshot: p.runtime.Current()}
+	rt := r.Snapshot.Runtime
+	if req.Debug {
+		r.Debug = &Debug{ConfigVersion: r.Snapshot.Version}
+	}
+	t := req.Tenant
+
+	var err error
+	r.ChatTemplate, err = p.prompts.Resolve(ctx, t.ID, req.Collection, prompt.KindChat, req.PromptTemplate)
+	if err != nil {
+		return nil, templateError(req.PromptTemplate, err)
+	}
+	r.RerankTemplate, err = p.prompts.Resolve(ctx, t.ID, req.Collection, prompt.KindRerank, req.RerankTemplate)
+	if err != nil {
+		return nil, templateError(req.RerankTemplate, err)
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("rag chat requested", "answer_id", r.ID, logging.Text("question", req.Question), "config_version", r.Snapshot.Version,
+		"prompt_template", r.ChatTemplate.Ref(), "rerank_template", r.RerankTemplate.Ref())
+
+	// query rewrite (선택): 짧거나 모호한 질문을 여러 query로 바꿔 검색
+	r.Queries = []string{req.Question}
+	rewrites := rt.QueryRewrites
+	if req.QueryRewrites != nil {
+		rewrites = *req.QueryRewrites
+	}
+	if rewrites > 0 {
+		start := time.Now()
+		queries, usage, err := p.rewrite(ctx, r, rewrites)
+		metrics.ObserveStage(metrics.StageRewrite, start)
+		if err != nil {
+			// rewrite는 보조 단계이므로 실패해도 원래 질문으로 계속 진행
+			logger.Warn("query rewrite failed, searching with the original question", "error", err)
+		} else {
+			r.Queries = append(r.Queries, queries...)
+			addUsage(&r.Usage, usage)
+		}
+		for i, q := range r.Queries {
+			logger.Debug("retrieval query", "index", i, logging.Text("query", q))
+		}
+	}
+
+	// 검색 방식: 요청 → collection 설정 → vector
+	if err := p.resolveMode(ctx, r); err != nil {
+		return nil, err
+	}
+	if r.Mode == ModeHyDE {
+		start := time.Now()
+		passage, usage, err := p.hypotheticalDocument(ctx, r)
+		metrics.ObserveStage(metrics.StageHyDE, start)
+		addUsage(&r.Usage, usage)
+		if err != nil {
+			logger.Warn("HyDE generation failed, searching with the question embedding", "error", err)
+			r.Mode = ModeVector
+		} else {
+			r.HyDEDocument = passage
+			logger.Debug("hypothetical document generated", "average", r.HyDEAverage, logging.Text("document", passage))
+		}
+	}
+
+	// 각 query를 병렬로 embedding/검색하고 결과를 합친다
+	// MMR이면 더 큰 후보 집합을 embedding과 함께 가져와 다양한 top-k를 고른다
+	r.MMR, r.MMRLambda = rt.MMREnabled, rt.MMRLambda
+	if req.MMR != nil {
+		r.MMR = *req.MMR
+	}
+	if req.MMRLambda != nil {
+		r.MMRLambda = *req.MMRLambda
+	}
+	limit := rt.SearchTopK
+	if r.MMR {
+		limit = max(rt.MMRCandidates, rt.SearchTopK)
+	}
+	r.Similar, err = p.searchAll(ctx, r, r.Queries, limit, r.MMR)
+	if err != nil {
+		return nil, err
+	}
+	if r.MMR {
+		if r.Debug != nil {
+			r.Debug.Candidates = hits(r.Similar)
+		}
+		r.Candidates = len(r.Similar)
+		r.Similar = mmr(r.Similar, rt.SearchTopK, r.MMRLambda)
+		for i := range r.Similar {
+			r.Similar[i].Embedding = nil
+		}
+		logger.Debug("mmr applied", "candidates", r.Candidates, "selected", len(r.Similar), "lambda", r.MMRLambda)
+	}
+	logger.Debug("similar documents found", "count", len(r.Similar))
+
+	// rerank 처리
+	opts := reranker.Options{
+		Model:        firstNonEmpty(t.RerankerModel, rt.RerankerModel),
+		Threshold:    rt.RerankThreshold,
+		MaxDocuments: rt.SearchTopK,
+		Template:     r.RerankTemplate,
+	}
+	if r.Debug != nil {
+		opts.Trace = &r.Debug.Rerank
+	}
+	var rerankUsage reranker.Usage
+	opts.Usage = &rerankUsage
+	start := time.Now()
+	r.Sources, err = p.reranker.Rerank(ctx, req.Question, r.Similar, opts)
+	metrics.ObserveStage(metrics.StageRerank, start)
+	addUsage(&r.Usage, chat.Usage{PromptTokens: rerankUsage.PromptTokens, CompletionTokens: rerankUsage.CompletionTokens})
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("documents reranked", "input", len(r.Similar), "selected", len(r.Sources))
+
+	if d := r.Debug; d != nil {
+		d.Mode = r.Mode
+		d.HyDEDocument = r.HyDEDocument
+		d.RerankInput = hits(r.Similar)
+		d.RerankOutput = r.Sources
+		d.explainDropped(r, limit)
+	}
+
+	return r, nil
+}
+
+// Generate asks the chat model for an answer. onDelta가 nil이 아니면 답변을 stream으로 전달한다.
+// 실패해도 중단 전까지의 답변을 Incomplete로 표시해 observer에 전달하고 error와 함께 반환한다.
+func (p *Pipeline) Generate(ctx context.Context, r *Retrieval, onDelta func(string) error) (*Result, error) {
+	rt := r.Snapshot.Runtime
+	t := r.Request.Tenant
+	model := firstNonEmpty(t.ChatModel, rt.LLMChatModel)
+
+	opts := chat.Options{
+		Model:        model,
+		SystemPrompt: firstNonEmpty(t.SystemPrompt, rt.SystemPrompt),
+		Template:     r.ChatTemplate,
+		Language:     r.Request.Language,
+		Generation:   r.Request.Generation,
+	}
+	if r.Debug != nil {
+		opts.Trace = &r.Debug.Chat
+	}
+	start := time.Now()
+	answer, usage, err := p.chat.ChatStream(ctx, r.Request.Question, r.Sources, opts, onDelta)
+	metrics.ObserveStage(metrics.StageLLM, start)
+	// quota에는 query rewrite, HyDE, rerank 토큰도 포함
+	addUsage(&usage, r.Usage)
+
+	result := &Result{
+		ID:      r.ID,
+		Answer:  answer,
+		Usage:   usage,
+		Sources: r.Sources,
+		Prompt:  r.ChatTemplate.Ref(),
+		Model:   model,
+		Debug:   r.Debug,
+	}
+	if err != nil {
+		// 중간에 끊긴 답변도 감사 로그에 남기고, 이미 사용한 토큰은 quota에 반영할 수 있도록 함께 반환
+		result.Incomplete = true
+		result.Error = err.Error()
+	}
+	for _, fn := range p.observers {
+		fn(ctx, r, result)
+	}
+	return result, err
+}
+
+// Run retrieves and generates a non-streamed answer
+func (p *Pipeline) Run(ctx context.Context, req Request) (*Result, error) {
+	r, err := p.Retrieve(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return p.Generate(ctx, r, nil)
+}
+
+// resolveMode picks the retrieval mode of the request or the collection
+func (p *Pipeline) resolveMode(ctx context.Context, r *Retrieval) error {
+	req := r.Request
+	settings, err := p.db.GetCollectionSettings(ctx, req.Tenant.ID, req.Collection)
+	if err != nil {
+		return err
+	}
+
+	r.Mode = firstNonEmpty(req.RetrievalMode, settings.RetrievalMode, ModeVector)
+	if r.Mode != ModeVector && r.Mode != ModeHyDE {
+		return &RequestError{Err: fmt.Errorf("unknown retrieval mode %q", r.Mode)}
+	}
+	r.HyDEAverage = settings.HyDEAverage
+	if req.HyDEAverage != nil {
+		r.HyDEAverage = *req.HyDEAverage
+	}
+	return nil
+}
+
+func newAnswerID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func addUsage(total *chat.Usage, usage chat.Usage) {
+	total.PromptTokens += usage.PromptTokens
+	total.CompletionTokens += usage.CompletionTokens
+}
+
+// RequestError marks errors caused by the request itself (잘못된 템플릿 이름 등)
+type RequestError struct {
+	Err error
+}
+
+func (e *RequestError) Error() string { return e.Err.Error() }
+
+func (e *RequestError) Unwrap() error { return e.Err }
+
+// templateError marks a template lookup error as a RequestError when the requested name or version is wrong.
+// DB 오류나 collection 바인딩이 가리키는 템플릿이 없는 경우는 서버 문제로 그대로 반환한다.
+func templateError(requested string, err error) error {
+	if requested != "" && (errors.Is(err, prompt.ErrNotFound) || errors.Is(err, prompt.ErrInvalidRef)) {
+		return &RequestError{Err: err}
+	}
+	return err
+}
+
+// firstNonEmpty returns the first non-empty value (tenant 설정 → 전역 runtime 설정 순)
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
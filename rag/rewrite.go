@@ -0,0 +1,165 @@
+package rag
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/hello/chat"
+	"example.com/hello/metrics"
+	"example.com/hello/prompt"
+	"example.com/hello/vector"
+)
+
+// rrfK is the rank constant of reciprocal rank fusion (일반적으로 쓰는 60)
+const rrfK = 60
+
+// listMarker matches numbering or bullets the model may add despite the instructions
+var listMarker = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s*`)
+
+// rewrite asks the chat model for n alternative queries for the question
+func (p *Pipeline) rewrite(ctx context.Context, r *Retrieval, n int) ([]string, chat.Usage, error) {
+	req := r.Request
+	tmpl, err := p.prompts.Resolve(ctx, req.Tenant.ID, req.Collection, prompt.KindRewrite, "")
+	if err != nil {
+		return nil, chat.Usage{}, err
+	}
+
+	data := prompt.NewData(req.Question, nil, req.Language, "")
+	data.Count = n
+	rendered, err := tmpl.Render(data)
+	if err != nil {
+		return nil, chat.Usage{}, err
+	}
+
+	content, usage, err := p.chat.Complete(ctx, []chat.Message{{Role: "user", Content: rendered}}, chat.Options{
+		Model: firstNonEmpty(req.Tenant.ChatModel, r.Snapshot.Runtime.LLMChatModel),
+	})
+	if err != nil {
+		return nil, chat.Usage{}, err
+	}
+	return parseQueries(content, req.Question, n), usage, nil
+}
+
+// parseQueries extracts up to n distinct queries (one per line) that differ from the original question
+func parseQueries(content, original string, n int) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(original)): true}
+	var queries []string
+	for _, line := range strings.Split(content, "\n") {
+		q := listMarker.ReplaceAllString(line, "")
+		q = strings.Trim(strings.TrimSpace(q), "\"'`")
+		key := strings.ToLower(q)
+		if q == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		queries = append(queries, q)
+		if len(queries) == n {
+			break
+		}
+	}
+	return queries
+}
+
+// searchAll embeds and searches every query in parallel and fuses the results
+func (p *Pipeline) searchAll(ctx context.Context, r *Retrieval, queries []string, limit int, withEmbeddings bool) ([]vector.Document, error) {
+	req := r.Request
+	results := make([][]vector.Document, len(queries))
+	errs := make([]error, len(queries))
+	if r.Debug != nil {
+		r.Debug.Searches = make([]SearchDebug, len(queries))
+	}
+
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// embedding api로 질의문 vector 데이터로 변환 (hyde 모드면 원래 질문 대신 가상 답변 문서)
+			start := time.Now()
+			var queryVector []float32
+			var err error
+			if i == 0 && r.HyDEDocument != "" {
+				queryVector, err = p.hydeVector(ctx, r)
+			} else {
+				queryVector, err = p.emb.GenerateEmbedding(ctx, q)
+			}
+			metrics.ObserveStage(metrics.StageEmbed, start)
+			// goroutine마다 자기 index에만 기록
+			var debug *SearchDebug
+			if r.Debug != nil {
+				debug = &r.Debug.Searches[i]
+				debug.Query = q
+				debug.EmbeddingMS = milliseconds(time.Since(start))
+			}
+			if err != nil {
+				errs[i] = err
+				if debug != nil {
+					debug.Error = err.Error()
+				}
+				return
+			}
+
+			// vector 데이터로 db 데이터 조회
+			start = time.Now()
+			results[i], errs[i] = p.db.SearchSimilar(ctx, queryVector, limit, vector.SearchFilter{
+				TenantID:       req.Tenant.ID,
+				Collection:     req.Collection,
+				Access:         req.Access,
+				WithEmbeddings: withEmbeddings,
+			})
+			metrics.ObserveStage(metrics.StageSearch, start)
+			if debug != nil {
+				debug.SearchMS = milliseconds(time.Since(start))
+				debug.Hits = hits(results[i])
+				if errs[i] != nil {
+					debug.Error = errs[i].Error()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 원래 질문의 검색이 실패하면 실패, rewrite된 query의 실패는 무시
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+	if len(queries) == 1 {
+		return results[0], nil
+	}
+	return fuse(results, limit), nil
+}
+
+// fuse merges ranked lists with reciprocal rank fusion, deduplicating by document ID
+func fuse(lists [][]vector.Document, limit int) []vector.Document {
+	scores := map[int]float64{}
+	docs := map[int]vector.Document{}
+	for _, list := range lists {
+		for rank, doc := range list {
+			scores[doc.ID] += 1.0 / float64(rrfK+rank+1)
+			// 여러 query에서 나온 문서는 가장 가까운 거리를 유지
+			if prev, ok := docs[doc.ID]; !ok || doc.Distance < prev.Distance {
+				docs[doc.ID] = doc
+			}
+		}
+	}
+
+	fused := make([]vector.Document, 0, len(docs))
+	for _, doc := range docs {
+		fused = append(fused, doc)
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if scores[fused[i].ID] != scores[fused[j].ID] {
+			return scores[fused[i].ID] > scores[fused[j].ID]
+		}
+		return fused[i].Distance < fused[j].Distance
+	})
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+	return fused
+}
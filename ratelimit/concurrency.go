@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrQueueFull    = errors.New("too many queued requests")
+	ErrQueueTimeout = errors.New("timed out waiting for a free slot")
+)
+
+// ConcurrencyLimiter bounds the number of concurrent LLM calls,
+// with a bounded wait queue in front of them
+type ConcurrencyLimiter struct {
+	slots    chan struct{}
+	maxQueue int64
+	maxWait  time.Duration
+	waiting  atomic.Int64
+}
+
+// NewConcurrencyLimiter creates a limiter with maxConcurrent slots and at most maxQueue waiters
+func NewConcurrencyLimiter(maxConcurrent, maxQueue int, maxWait time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		slots:    make(chan struct{}, maxConcurrent),
+		maxQueue: int64(maxQueue),
+		maxWait:  maxWait,
+	}
+}
+
+// Acquire waits for a free slot. 반환된 release 함수는 반드시 호출해야 한다.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+
+	if l.waiting.Add(1) > l.maxQueue {
+		l.waiting.Add(-1)
+		return nil, ErrQueueFull
+	}
+	defer l.waiting.Add(-1)
+
+	timer := time.NewTimer(l.maxWait)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	case <-timer.C:
+		return nil, ErrQueueTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// InFlight returns the number of occupied slots
+func (l *ConcurrencyLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Waiting returns the number of queued requests
+func (l *ConcurrencyLimiter) Waiting() int {
+	return int(l.waiting.Load())
+}
+
+func (l *ConcurrencyLimiter) release() {
+	<-l.slots
+}
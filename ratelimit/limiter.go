@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleTTL is how long an unused client bucket is kept in memory
+const idleTTL = 10 * time.Minute
+
+type clientBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter is a token-bucket rate limiter keyed by client (API key or IP)
+type Limiter struct {
+	mu        sync.Mutex
+	rps       rate.Limit
+	burst     int
+	buckets   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+// NewLimiter creates a limiter allowing rps requests per second with the given burst per client
+func NewLimiter(rps float64, burst int) *Limiter {
+	return &Limiter{
+		rps:       rate.Limit(rps),
+		burst:     burst,
+		buckets:   make(map[string]*clientBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow consumes a token for client. 토큰이 없으면 다음 토큰까지 기다려야 하는 시간을 반환한다.
+func (l *Limiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &clientBucket{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.buckets[client] = bucket
+	}
+	bucket.lastSeen = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// SetLimit changes the rate for every client
+func (l *Limiter) SetLimit(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rps = rate.Limit(rps)
+	l.burst = burst
+	for _, bucket := range l.buckets {
+		bucket.limiter.SetLimit(l.rps)
+		bucket.limiter.SetBurst(burst)
+	}
+}
+
+// sweep removes idle buckets (최대 1분에 한 번)
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	for client, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) > idleTTL {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
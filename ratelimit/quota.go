@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// addTimeout bounds recording usage after the answer was generated
+const addTimeout = 5 * time.Second
+
+// QuotaTracker tracks daily LLM token usage per tenant (UTC 기준 하루).
+// 사용량은 Postgres에 저장하므로 재시작해도 유지되고 여러 replica가 같은 quota를 공유한다.
+type QuotaTracker struct {
+	pool *pgxpool.Pool
+
+	mu         sync.Mutex
+	dailyLimit int
+}
+
+// NewQuotaTracker creates a tracker. dailyLimit이 0이면 제한 없이 사용량만 집계한다.
+func NewQuotaTracker(pool *pgxpool.Pool, dailyLimit int) *QuotaTracker {
+	return &QuotaTracker{pool: pool, dailyLimit: dailyLimit}
+}
+
+// Migrate creates the daily usage table
+func (q *QuotaTracker) Migrate(ctx context.Context) error {
+	_, err := q.pool.Exec(ctx, `
+        CREATE TABLE IF NOT EXISTS token_usage (
+            tenant_id TEXT NOT NULL,
+            day       DATE NOT NULL,
+            tokens    BIGINT NOT NULL DEFAULT 0,
+            PRIMARY KEY (tenant_id, day)
+        )
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to migrate token usage: %w", err)
+	}
+	return nil
+}
+
+// Allow reports whether tenant still has quota left today, and when the quota resets.
+// limit이 0이면 기본 daily limit을 사용한다 (tenant별 quota).
+func (q *QuotaTracker) Allow(ctx context.Context, tenant string, limit int) (bool, time.Duration, error) {
+	if limit == 0 {
+		q.mu.Lock()
+		limit = q.dailyLimit
+		q.mu.Unlock()
+	}
+	if limit <= 0 {
+		return true, 0, nil
+	}
+
+	now := time.Now().UTC()
+	used, err := q.used(ctx, tenant, now)
+	if err != nil {
+		return false, 0, err
+	}
+	if used < limit {
+		return true, 0, nil
+	}
+	return false, untilMidnight(now), nil
+}
+
+// Add records tokens used by tenant.
+// client가 연결을 끊은 뒤에도 기록되도록 요청 context의 cancel은 무시한다.
+func (q *QuotaTracker) Add(ctx context.Context, tenant string, tokens int) error {
+	if tokens <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), addTimeout)
+	defer cancel()
+
+	_, err := q.pool.Exec(ctx, `
+        INSERT INTO token_usage (tenant_id, day, tokens) VALUES ($1, $2, $3)
+        ON CONFLICT (tenant_id, day) DO UPDATE SET tokens = token_usage.tokens + EXCLUDED.tokens
+    `, tenant, day(time.Now().UTC()), tokens)
+	if err != nil {
+		return fmt.Errorf("failed to record token usage: %w", err)
+	}
+	return nil
+}
+
+// Used returns the tokens used by tenant today
+func (q *QuotaTracker) Used(ctx context.Context, tenant string) (int, error) {
+	return q.used(ctx, tenant, time.Now().UTC())
+}
+
+// SetDailyLimit changes the default daily limit
+func (q *QuotaTracker) SetDailyLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dailyLimit = limit
+}
+
+func (q *QuotaTracker) used(ctx context.Context, tenant string, now time.Time) (int, error) {
+	var used int
+	err := q.pool.QueryRow(ctx, `
+        SELECT COALESCE(SUM(tokens), 0) FROM token_usage WHERE tenant_id = $1 AND day = $2
+    `, tenant, day(now)).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get token usage: %w", err)
+	}
+	return used, nil
+}
+
+// day truncates now to its UTC date
+func day(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func untilMidnight(now time.Time) time.Duration {
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return midnight.Sub(now)
+}
@@ -6,11 +6,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 
+	"example.com/hello/logging"
+	"example.com/hello/metrics"
+	"example.com/hello/prompt"
+	"example.com/hello/tracing"
 	"example.com/hello/vector"
 )
 
@@ -33,6 +37,10 @@ type RerankResponse struct {
 	Done       bool   `json:"done"`
 	DoneReason string `json:"done_reason,omitempty"`
 	Context    []int  `json:"context,omitempty"`
+
+	// 토큰 사용량 (Ollama)
+	PromptEvalCount int `json:"prompt_eval_count,omitempty"`
+	EvalCount       int `json:"eval_count,omitempty"`
 }
 
 // Rerank 결과 구조체
@@ -44,9 +52,58 @@ type RerankResult struct {
 }
 
 type RankedDocument struct {
-	Index   int     `json:"index"`
-	Content string  `json:"content"`
-	Score   float64 `json:"score"`
+	Index      int     `json:"index"`
+	DocumentID int     `json:"document_id"`
+	Collection string  `json:"collection,omitempty"`
+	Content    string  `json:"content"`
+	Score      float64 `json:"score"`
+	// Title, URL은 수집한 문서의 metadata (웹 문서면 클릭 가능한 citation)
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+func newRankedDocument(index int, doc vector.Document, score float64) RankedDocument {
+	return RankedDocument{
+		Index:      index,
+		DocumentID: doc.ID,
+		Collection: doc.Collection,
+		Content:    doc.Content,
+		Score:      score,
+		Title:      doc.MetaString(vector.MetaTitle),
+		URL:        doc.MetaString(vector.MetaURL),
+	}
+}
+
+// Options overrides per-request settings (tenant별 모델, hot reload된 threshold)
+type Options struct {
+	Model string
+	// Threshold 이하의 점수를 받은 문서는 버린다 (RERANK_THRESHOLD, 0이면 점수가 0보다 큰 문서 모두)
+	Threshold float64
+	// MaxDocuments는 모델에 보낼 최대 문서 수 (SEARCH_TOP_K, 0이면 전부)
+	MaxDocuments int
+	// Template이 nil이면 기본 rerank 템플릿 사용
+	Template *prompt.Template
+	// Trace가 nil이 아니면 prompt, 원본 응답, threshold 미달을 포함한 모든 점수를 기록한다 (debug 요청)
+	Trace *Trace
+	// Usage가 nil이 아니면 rerank 호출의 토큰 사용량을 기록한다 (quota)
+	Usage *Usage
+}
+
+// Usage is the token count of one rerank call
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Trace records one rerank call
+type Trace struct {
+	Prompt    string  `json:"prompt"`
+	Response  string  `json:"response"`
+	Threshold float64 `json:"threshold"`
+	// Limit은 모델에 보낼 수 있는 최대 문서 수, Input은 실제로 보낸 문서 수 (앞에서부터)
+	Limit  int              `json:"limit"`
+	Input  int              `json:"input"`
+	Scores []RankedDocument `json:"scores"`
 }
 
 // NewService creates a new embedding service
@@ -59,14 +116,35 @@ func NewService(apiURL, model string) *Service {
 }
 
 // 질문과 document로 유사도 리스트를 뽑는다.
-func (s *Service) Rerank(ctx context.Context, content string, documents []vector.Document) ([]RankedDocument, error) {
-	var results []RankedDocument
+func (s *Service) Rerank(ctx context.Context, content string, documents []vector.Document, opts Options) (results []RankedDocument, err error) {
+	model := s.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	threshold := opts.Threshold
+	if opts.MaxDocuments > 0 && len(documents) > opts.MaxDocuments {
+		documents = documents[:opts.MaxDocuments]
+	}
+
+	ctx, span := tracing.StartClientSpan(ctx, "example.com/hello/reranker", "reranker.Rerank", model)
+	defer func() { tracing.End(span, err) }()
+	span.SetAttributes(tracing.AttrDocuments.Int(len(documents)))
 
-	prompt := s.buildPrompt(content, documents)
+	rerankPrompt, err := s.buildPrompt(content, documents, opts.Template)
+	if err != nil {
+		return nil, err
+	}
+	trace := opts.Trace
+	if trace != nil {
+		trace.Prompt = rerankPrompt
+		trace.Threshold = threshold
+		trace.Limit = opts.MaxDocuments
+		trace.Input = len(documents)
+	}
 
 	reqData := RerankRequest{
-		Model:  s.model,
-		Prompt: prompt,
+		Model:  model,
+		Prompt: rerankPrompt,
 		Stream: false,
 	}
 
@@ -84,10 +162,12 @@ func (s *Service) Rerank(ctx context.Context, content string, documents []vector
 	// 헤더 설정
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("ngrok-skip-browser-warning", "true")
+	tracing.Inject(ctx, req)
 
 	// 요청 전송
 	resp, err := s.client.Do(req)
 	if err != nil {
+		metrics.RecordUpstreamError("reranker", metrics.TransportReason(err))
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
@@ -95,6 +175,7 @@ func (s *Service) Rerank(ctx context.Context, content string, documents []vector
 	// 상태 코드 확인
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		metrics.RecordUpstreamError("reranker", "status_"+strconv.Itoa(resp.StatusCode))
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 	// 원시 응답 읽기
@@ -106,32 +187,48 @@ func (s *Service) Rerank(ctx context.Context, content string, documents []vector
 	// 1단계: 외부 JSON 파싱
 	var response RerankResponse
 	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		metrics.RecordUpstreamError("reranker", "decode")
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+	span.SetAttributes(
+		tracing.AttrPromptTokens.Int(response.PromptEvalCount),
+		tracing.AttrCompletionTokens.Int(response.EvalCount),
+	)
+	if opts.Usage != nil {
+		*opts.Usage = Usage{PromptTokens: response.PromptEvalCount, CompletionTokens: response.EvalCount}
+	}
+
+	if trace != nil {
+		trace.Response = response.Response
+	}
 
-	log.Printf("Response field (escaped JSON): |%s|", response.Response)
+	logger := logging.FromContext(ctx)
 	cleanResponse := strings.TrimSpace(response.Response)
 	cleanResponse = strings.Trim(cleanResponse, "\"'`") // 따옴표 제거
 
-	log.Printf("Response field (escaped JSON): |%s|", cleanResponse)
+	logger.Debug("rerank response received", "model", model, "response", cleanResponse)
 	// 2단계: response 필드 내부의 이스케이프된 JSON 파싱
 	var rerankResult RerankResult
 	if err := json.Unmarshal([]byte(cleanResponse), &rerankResult); err != nil {
+		metrics.RecordUpstreamError("reranker", "invalid_json")
 		return nil, fmt.Errorf("failed to parse rerank result: %w, response was: %s", err, response.Response)
 	}
 
 	for _, rerank := range rerankResult.Results {
-		log.Println(rerank)
-		if rerank.Score > 0.6 {
-			document := RankedDocument{
-				Index:   rerank.Index,
-				Content: documents[rerank.Index].Content,
-				Score:   rerank.Score,
-			}
-			log.Printf("Selected document - Content: %s, Score: %.3f\n", document.Content, document.Score)
+		if rerank.Index < 0 || rerank.Index >= len(documents) {
+			logger.Warn("rerank returned out of range index", "index", rerank.Index, "documents", len(documents))
+			continue
+		}
+		document := newRankedDocument(rerank.Index, documents[rerank.Index], rerank.Score)
+		if trace != nil {
+			trace.Scores = append(trace.Scores, document)
+		}
+		if rerank.Score > threshold {
+			logger.Debug("rerank selected document", "index", document.Index, "score", document.Score, logging.Text("content", document.Content))
 			results = append(results, document)
 		}
 	}
+	metrics.RecordRerank(len(results), len(documents)-len(results))
 
 	return results, nil
 }
@@ -177,59 +274,16 @@ func min(a, b int) int {
 	return b
 }
 
-func truncate(s string, maxChars int) string {
-	if len(s) > maxChars {
-		return s[:maxChars]
+func (s *Service) buildPrompt(query string, documents []vector.Document, tmpl *prompt.Template) (string, error) {
+	// 문서 수는 호출자가 제한한다 (Options.MaxDocuments). 문서 길이 제한과 출력 형식은 템플릿에서 정한다
+	if tmpl == nil {
+		tmpl = prompt.DefaultFor(prompt.KindRerank)
 	}
-	return s
-}
-func (s *Service) buildPrompt(query string, documents []vector.Document) string {
-	const (
-		maxDocs     = 3   // LLM rerank 대상 최대 개수
-		maxDocChars = 200 // 문서당 최대 길이
-	)
-
-	// 1. 문서 수 제한 (Top-K)
-	documents = documents[:min(len(documents), maxDocs)]
-
-	var sb strings.Builder
-
-	// 2. 시스템 지시문 (최소화)
-	sb.WriteString("You are a reranking system.\n")
-	sb.WriteString("Score each document for relevance to the query.\n\n")
-
-	// 3. 강제 규칙
-	sb.WriteString("RULES:\n")
-	sb.WriteString("OUTPUT ONLY VALID JSON. NO EXTRA TEXT.\n\n")
-	sb.WriteString("- No markdown, no extra text\n")
-	sb.WriteString(fmt.Sprintf("- Exactly %d results\n", len(documents)))
-	sb.WriteString("- Score range: 0.0 to 1.0\n")
-	sb.WriteString("- Consider both semantic relevance AND vector distance\n")
-	sb.WriteString("- Start with { and end with }\n\n")
-
-	// 4. Query
-	sb.WriteString("Query:\n")
-	sb.WriteString(query)
-	sb.WriteString("\n\n")
-
-	// 5. Documents (truncate + 압축 포맷)
-	sb.WriteString("Documents:\n")
+	docs := make([]prompt.Document, len(documents))
 	for i, doc := range documents {
-		content := truncate(doc.Content, maxDocChars)
-		sb.WriteString(fmt.Sprintf("D%d (distance: %.3f): %s\n", i, 1-doc.Distance, content))
+		docs[i] = prompt.Document{Index: i, Content: doc.Content, Distance: doc.Distance, Similarity: 1 - doc.Distance}
 	}
-
-	// 6. 출력 포맷 명시
-	sb.WriteString("\nOutput JSON format:\n")
-	sb.WriteString(`{"results":[`)
-	for i := range documents {
-		if i > 0 {
-			sb.WriteString(",")
-		}
-		sb.WriteString(fmt.Sprintf(`{"index":%d,"score":0.0}`, i))
-	}
-	sb.WriteString(`]}`)
-	return sb.String()
+	return tmpl.Render(prompt.NewData(query, docs, "", ""))
 }
 
 // FastRerank - 규칙 기반 빠른 reranking
@@ -238,7 +292,7 @@ func (s *Service) FastRerank(ctx context.Context, query string, documents []vect
 
 	var ranked []RankedDocument
 
-	for _, doc := range documents {
+	for i, doc := range documents {
 		// 1. 벡터 유사도 (이미 계산됨)
 		vectorScore := 1.0 - doc.Distance
 
@@ -251,10 +305,7 @@ func (s *Service) FastRerank(ctx context.Context, query string, documents []vect
 		// 최종 점수 = 벡터 70% + 키워드 20% + 길이 10%
 		finalScore := vectorScore*0.5 + keywordScore*0.4 + lengthPenalty*0.1
 
-		ranked = append(ranked, RankedDocument{
-			Content: doc.Content,
-			Score:   finalScore,
-		})
+		ranked = append(ranked, newRankedDocument(i, doc, finalScore))
 	}
 
 	// 점수 기준 정렬
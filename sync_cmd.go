@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"example.com/hello/config"
+	"example.com/hello/embedding"
+	"example.com/hello/ingest"
+	"example.com/hello/logging"
+	"example.com/hello/tenant"
+	"example.com/hello/vector"
+)
+
+const syncUsage = `usage:
+  hello sync -dir ./docs [-git] [-source name] [-collection name] [-tenant id] [-include "*.md,*.txt"] [-dry-run] [-interval 10m] [-- config flags]`
+
+// runSync mirrors a directory or git checkout into a collection.
+// -interval을 주면 종료될 때까지 주기적으로 다시 동기화한다 (background job).
+func runSync(args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	dir := flags.String("dir", "", "directory or git checkout to sync")
+	git := flags.Bool("git", false, "sync only files tracked by git (git ls-files)")
+	source := flags.String("source", "", "stable name the files are stored under (default: the origin remote with -git, otherwise the absolute directory)")
+	tenantID := flags.String("tenant", tenant.DefaultID, "tenant the documents belong to")
+	collection := flags.String("collection", vector.DefaultCollection, "collection the documents are stored in")
+	include := flags.String("include", strings.Join(ingest.DefaultInclude, ","), "comma separated file name patterns")
+	chunkSize := flags.Int("chunk-size", ingest.DefaultChunkSize, "maximum chunk size in characters")
+	chunkOverlap := flags.Int("chunk-overlap", ingest.DefaultChunkOverlap, "characters repeated between chunks")
+	dryRun := flags.Bool("dry-run", false, "report changes without writing to the database")
+	interval := flags.Duration("interval", 0, "sync again every interval until interrupted (0 = once)")
+	jsonOut := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New(syncUsage)
+	}
+
+	// "--" 뒤의 인자는 서버와 같은 설정 flag
+	cfg, err := config.Load(flags.Args())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogDebug); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	db, err := vector.New(connectCtx, cfg.GetDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(connectCtx); err != nil {
+		return err
+	}
+	if err := checkTenant(connectCtx, db, *tenantID); err != nil {
+		return err
+	}
+
+	writer := ingest.NewWriter(db, embedding.NewService(cfg.EmbeddingAPIURL, cfg.EmbeddingModel))
+	writer.ChunkSize = *chunkSize
+	writer.ChunkOverlap = *chunkOverlap
+	syncer := ingest.NewSyncer(db, writer)
+	opts := ingest.SyncOptions{
+		TenantID:   *tenantID,
+		Collection: *collection,
+		Root:       *dir,
+		Git:        *git,
+		Source:     *source,
+		Include:    splitList(*include),
+		DryRun:     *dryRun,
+	}
+
+	for {
+		report, err := syncer.Sync(ctx, opts)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil
+		case err != nil && *interval == 0:
+			return err
+		case err != nil:
+			slog.Warn("Sync failed", "dir", *dir, "error", err)
+		default:
+			if err := printSyncReport(report, *jsonOut); err != nil {
+				return err
+			}
+			if *interval == 0 && len(report.Errors) > 0 {
+				return fmt.Errorf("%d files failed to sync", len(report.Errors))
+			}
+		}
+		if *interval == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+func printSyncReport(report *ingest.SyncReport, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+	fmt.Printf("synced %s", report.Root)
+	if report.Commit != "" {
+		fmt.Printf(" @ %s", report.Commit)
+	}
+	fmt.Printf(": %d added, %d updated, %d deleted, %d unchanged, %d chunks\n",
+		len(report.Added), len(report.Updated), len(report.Deleted), report.Unchanged, report.Chunks)
+	for _, p := range report.Added {
+		fmt.Println("  +", p)
+	}
+	for _, p := range report.Updated {
+		fmt.Println("  ~", p)
+	}
+	for _, p := range report.Deleted {
+		fmt.Println("  -", p)
+	}
+	for _, e := range report.Errors {
+		fmt.Printf("  ! %s: %s\n", e.Path, e.Error)
+	}
+	return nil
+}
+
+// checkTenant fails unless tenantID exists (HTTP API처럼 없는 tenant에는 문서를 저장하지 않는다)
+func checkTenant(ctx context.Context, db *vector.VectorDB, tenantID string) error {
+	tenantStore := tenant.NewStore(db.Pool())
+	if err := tenantStore.Migrate(ctx); err != nil {
+		return err
+	}
+	if _, err := tenantStore.Get(ctx, tenantID); err != nil {
+		return fmt.Errorf("failed to get tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
+
+// splitList splits a comma separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
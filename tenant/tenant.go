@@ -0,0 +1,181 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultID is the tenant that owns data created before multi-tenancy
+const DefaultID = "default"
+
+// cacheTTL is how long a tenant configuration is cached in memory
+const cacheTTL = 30 * time.Second
+
+var (
+	ErrNotFound  = errors.New("tenant not found")
+	validIDRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+)
+
+// Tenant represents an isolated team with its own documents, models, prompt and quota
+type Tenant struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	ChatModel       string `json:"chat_model,omitempty"`
+	RerankerModel   string `json:"reranker_model,omitempty"`
+	SystemPrompt    string `json:"system_prompt,omitempty"`
+	DailyTokenQuota int    `json:"daily_token_quota"`
+	// AuditRetentionDays가 0이면 전역 AUDIT_RETENTION_DAYS 사용
+	AuditRetentionDays int       `json:"audit_retention_days"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Store stores tenants in Postgres
+type Store struct {
+	pool *pgxpool.Pool
+
+	mu    sync.Mutex
+	cache map[string]cachedTenant
+}
+
+type cachedTenant struct {
+	tenant    *Tenant
+	fetchedAt time.Time
+}
+
+// NewStore creates a new tenant store
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{
+		pool:  pool,
+		cache: make(map[string]cachedTenant),
+	}
+}
+
+// Migrate creates the tenants table and the default tenant
+func (s *Store) Migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS tenants (
+            id                TEXT PRIMARY KEY,
+            name              TEXT NOT NULL,
+            chat_model        TEXT NOT NULL DEFAULT '',
+            reranker_model    TEXT NOT NULL DEFAULT '',
+            system_prompt     TEXT NOT NULL DEFAULT '',
+            daily_token_quota INTEGER NOT NULL DEFAULT 0,
+            created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+        )`,
+		`ALTER TABLE tenants ADD COLUMN IF NOT EXISTS audit_retention_days INTEGER NOT NULL DEFAULT 0`,
+		`INSERT INTO tenants (id, name) VALUES ('default', 'Default') ON CONFLICT (id) DO NOTHING`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate tenants: %w", err)
+		}
+	}
+	return nil
+}
+
+// Get returns a tenant by ID (결과는 잠시 캐시된다)
+func (s *Store) Get(ctx context.Context, id string) (*Tenant, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[id]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < cacheTTL {
+		return cached.tenant, nil
+	}
+
+	var t Tenant
+	err := s.pool.QueryRow(ctx, `
+        SELECT id, name, chat_model, reranker_model, system_prompt, daily_token_quota, audit_retention_days, created_at
+        FROM tenants
+        WHERE id = $1
+    `, id).Scan(&t.ID, &t.Name, &t.ChatModel, &t.RerankerModel, &t.SystemPrompt, &t.DailyTokenQuota,
+		&t.AuditRetentionDays, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	s.mu.Lock()
+	s.cache[id] = cachedTenant{tenant: &t, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return &t, nil
+}
+
+// List returns every tenant
+func (s *Store) List(ctx context.Context) ([]Tenant, error) {
+	rows, err := s.pool.Query(ctx, `
+        SELECT id, name, chat_model, reranker_model, system_prompt, daily_token_quota, audit_retention_days, created_at
+        FROM tenants
+        ORDER BY id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []Tenant
+	for rows.Next() {
+		var t Tenant
+		if err := rows.Scan(&t.ID, &t.Name, &t.ChatModel, &t.RerankerModel, &t.SystemPrompt,
+			&t.DailyTokenQuota, &t.AuditRetentionDays, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+// Save creates or updates a tenant
+func (s *Store) Save(ctx context.Context, t *Tenant) error {
+	if !validIDRegex.MatchString(t.ID) {
+		return fmt.Errorf("invalid tenant ID %q", t.ID)
+	}
+	if t.DailyTokenQuota < 0 {
+		return fmt.Errorf("daily_token_quota must not be negative")
+	}
+	if t.AuditRetentionDays < 0 {
+		return fmt.Errorf("audit_retention_days must not be negative")
+	}
+
+	err := s.pool.QueryRow(ctx, `
+        INSERT INTO tenants (id, name, chat_model, reranker_model, system_prompt, daily_token_quota, audit_retention_days)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (id) DO UPDATE SET
+            name = EXCLUDED.name,
+            chat_model = EXCLUDED.chat_model,
+            reranker_model = EXCLUDED.reranker_model,
+            system_prompt = EXCLUDED.system_prompt,
+            daily_token_quota = EXCLUDED.daily_token_quota,
+            audit_retention_days = EXCLUDED.audit_retention_days
+        RETURNING created_at
+    `, t.ID, t.Name, t.ChatModel, t.RerankerModel, t.SystemPrompt, t.DailyTokenQuota, t.AuditRetentionDays).Scan(&t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save tenant: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.cache, t.ID)
+	s.mu.Unlock()
+	return nil
+}
+
+type contextKey struct{}
+
+// WithTenant returns a context carrying the tenant
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant stored in ctx, if any
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(contextKey{}).(*Tenant)
+	return t, ok
+}
@@ -0,0 +1,55 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"example.com/hello/internal/dbtest"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testStore connects to TEST_DATABASE_URL, 없으면 test를 건너뛴다
+func testStore(t *testing.T) (*Store, *pgxpool.Pool) {
+	t.Helper()
+	pool := dbtest.Pool(t)
+	store := NewStore(pool)
+	dbtest.Migrate(t, store)
+	return store, pool
+}
+
+// TestStoreIsolation checks that one tenant's settings are never returned for another
+func TestStoreIsolation(t *testing.T) {
+	store, pool := testStore(t)
+	ctx := context.Background()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	a := &Tenant{ID: "test-a-" + suffix, Name: "A", ChatModel: "model-a", SystemPrompt: "tenant A prompt", DailyTokenQuota: 10}
+	b := &Tenant{ID: "test-b-" + suffix, Name: "B"}
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), "DELETE FROM tenants WHERE id = ANY($1)", []string{a.ID, b.ID})
+	})
+	for _, tenant := range []*Tenant{a, b} {
+		if err := store.Save(ctx, tenant); err != nil {
+			t.Fatalf("Save %s: %v", tenant.ID, err)
+		}
+	}
+
+	// A를 먼저 조회해 cache에 올린 뒤 B를 조회
+	if _, err := store.Get(ctx, a.ID); err != nil {
+		t.Fatalf("Get %s: %v", a.ID, err)
+	}
+	got, err := store.Get(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("Get %s: %v", b.ID, err)
+	}
+	if got.ID != b.ID || got.ChatModel != "" || got.SystemPrompt != "" || got.DailyTokenQuota != 0 {
+		t.Errorf("Get %s returned settings of another tenant: %+v", b.ID, got)
+	}
+
+	if _, err := store.Get(ctx, "test-missing-"+suffix); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of an unknown tenant: err = %v, want ErrNotFound", err)
+	}
+}
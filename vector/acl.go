@@ -0,0 +1,39 @@
+package vector
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Access describes who is reading documents.
+// 문서의 allowed_users/allowed_groups가 모두 비어 있으면 공개 문서로 취급한다.
+type Access struct {
+	User   string
+	Groups []string
+	// All bypasses document ACLs (platform admin, eval)
+	All bool
+}
+
+// CanRead reports whether the reader may see doc
+func (a Access) CanRead(doc *Document) bool {
+	if a.All || (len(doc.AllowedUsers) == 0 && len(doc.AllowedGroups) == 0) {
+		return true
+	}
+	if a.User != "" && slices.Contains(doc.AllowedUsers, a.User) {
+		return true
+	}
+	for _, group := range a.Groups {
+		if slices.Contains(doc.AllowedGroups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// aclCondition is the SQL equivalent of CanRead.
+// $all, $user, $groups 순서의 placeholder 번호를 받는다.
+func aclCondition(all, user, groups int) string {
+	return fmt.Sprintf(`($%d OR (cardinality(allowed_users) = 0 AND cardinality(allowed_groups) = 0)
+            OR ($%d <> '' AND $%d = ANY(allowed_users))
+            OR allowed_groups && $%d::text[])`, all, user, user, groups)
+}
@@ -0,0 +1,71 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CollectionSettings are per-collection retrieval options
+type CollectionSettings struct {
+	Collection string `json:"collection"`
+	// RetrievalMode가 비어 있으면 기본 vector 검색
+	RetrievalMode string `json:"retrieval_mode"`
+	// HyDEAverage는 HyDE 문서 embedding을 질문 embedding과 평균낸다
+	HyDEAverage bool `json:"hyde_average"`
+}
+
+// GetCollectionSettings returns the settings of a collection (없으면 기본값)
+func (db *VectorDB) GetCollectionSettings(ctx context.Context, tenantID, collection string) (CollectionSettings, error) {
+	settings := CollectionSettings{Collection: collectionOrDefault(collection)}
+	err := db.pool.QueryRow(ctx, `
+        SELECT retrieval_mode, hyde_average
+        FROM collection_settings
+        WHERE tenant_id = $1 AND collection = $2
+    `, tenantOrDefault(tenantID), settings.Collection).Scan(&settings.RetrievalMode, &settings.HyDEAverage)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return settings, fmt.Errorf("failed to get collection settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveCollectionSettings creates or updates the settings of a collection
+func (db *VectorDB) SaveCollectionSettings(ctx context.Context, tenantID string, settings CollectionSettings) error {
+	_, err := db.pool.Exec(ctx, `
+        INSERT INTO collection_settings (tenant_id, collection, retrieval_mode, hyde_average)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (tenant_id, collection) DO UPDATE SET
+            retrieval_mode = EXCLUDED.retrieval_mode,
+            hyde_average = EXCLUDED.hyde_average
+    `, tenantOrDefault(tenantID), collectionOrDefault(settings.Collection), settings.RetrievalMode, settings.HyDEAverage)
+	if err != nil {
+		return fmt.Errorf("failed to save collection settings: %w", err)
+	}
+	return nil
+}
+
+// ListCollectionSettings returns the settings of every configured collection of tenantID
+func (db *VectorDB) ListCollectionSettings(ctx context.Context, tenantID string) ([]CollectionSettings, error) {
+	rows, err := db.pool.Query(ctx, `
+        SELECT collection, retrieval_mode, hyde_average
+        FROM collection_settings
+        WHERE tenant_id = $1
+        ORDER BY collection
+    `, tenantOrDefault(tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection settings: %w", err)
+	}
+	defer rows.Close()
+
+	var list []CollectionSettings
+	for rows.Next() {
+		var settings CollectionSettings
+		if err := rows.Scan(&settings.Collection, &settings.RetrievalMode, &settings.HyDEAverage); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		list = append(list, settings)
+	}
+	return list, rows.Err()
+}
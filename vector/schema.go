@@ -0,0 +1,46 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultCollection is used when a document is stored without a collection
+const DefaultCollection = "default"
+
+// migrations are applied in order at startup. 모든 문장은 여러 번 실행해도 안전해야 한다.
+var migrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS vector`,
+	`CREATE TABLE IF NOT EXISTS documents (
+        id         SERIAL PRIMARY KEY,
+        content    TEXT NOT NULL,
+        embedding  vector(1024) NOT NULL,
+        metadata   JSONB,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    )`,
+	`ALTER TABLE documents ADD COLUMN IF NOT EXISTS collection TEXT NOT NULL DEFAULT 'default'`,
+	`CREATE INDEX IF NOT EXISTS documents_collection_idx ON documents (collection)`,
+	`ALTER TABLE documents ADD COLUMN IF NOT EXISTS allowed_users TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE documents ADD COLUMN IF NOT EXISTS allowed_groups TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE documents ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default'`,
+	`DROP INDEX IF EXISTS documents_collection_idx`,
+	`CREATE INDEX IF NOT EXISTS documents_tenant_collection_idx ON documents (tenant_id, collection)`,
+	`CREATE TABLE IF NOT EXISTS collection_settings (
+        tenant_id      TEXT NOT NULL,
+        collection     TEXT NOT NULL,
+        retrieval_mode TEXT NOT NULL DEFAULT '',
+        hyde_average   BOOLEAN NOT NULL DEFAULT false,
+        PRIMARY KEY (tenant_id, collection)
+    )`,
+	`CREATE INDEX IF NOT EXISTS documents_source_path_idx ON documents (tenant_id, collection, (metadata->>'source_path'))`,
+}
+
+// Migrate creates or upgrades the database schema
+func (db *VectorDB) Migrate(ctx context.Context) error {
+	for i, stmt := range migrations {
+		if _, err := db.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+)
+
+// Metadata keys of documents ingested from an external source.
+// 같은 source_root/source_path의 문서(chunk)는 다시 수집할 때 통째로 교체된다.
+const (
+	MetaSourceRoot  = "source_root"
+	MetaSourcePath  = "source_path"
+	MetaSourceHash  = "source_hash"
+	MetaSourceMTime = "source_mtime"
+	MetaChunk       = "chunk"
+	// MetaTitle, MetaURL은 citation에 그대로 노출된다
+	MetaTitle = "title"
+	MetaURL   = "url"
+	// MetaFetchedURL은 web 페이지를 가져온 URL (canonical URL인 source_path와 다를 수 있다)
+	MetaFetchedURL = "fetched_url"
+)
+
+// MetaString returns a string metadata value of doc ("" if missing)
+func (d Document) MetaString(key string) string {
+	v, _ := d.Metadata[key].(string)
+	return v
+}
+
+// SourceState is the stored state of one ingested source
+type SourceState struct {
+	Path    string
+	Hash    string
+	MTime   string
+	Fetched string
+	Chunks  int
+}
+
+// Sources returns the sources of root stored in a collection, keyed by source_path
+func (db *VectorDB) Sources(ctx context.Context, tenantID, collection, root string) (map[string]SourceState, error) {
+	rows, err := db.pool.Query(ctx, `
+        SELECT metadata->>'source_path', max(metadata->>'source_hash'), max(metadata->>'source_mtime'),
+               max(metadata->>'fetched_url'), count(*)
+        FROM documents
+        WHERE tenant_id = $1 AND collection = $2 AND metadata->>'source_root' = $3 AND metadata ? 'source_path'
+        GROUP BY 1
+    `, tenantOrDefault(tenantID), collectionOrDefault(collection), root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := map[string]SourceState{}
+	for rows.Next() {
+		var s SourceState
+		var hash, mtime, fetched *string
+		if err := rows.Scan(&s.Path, &hash, &mtime, &fetched, &s.Chunks); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if hash != nil {
+			s.Hash = *hash
+		}
+		if mtime != nil {
+			s.MTime = *mtime
+		}
+		if fetched != nil {
+			s.Fetched = *fetched
+		}
+		sources[s.Path] = s
+	}
+	return sources, rows.Err()
+}
+
+// ReplaceSource deletes the documents of a source and inserts docs in one transaction
+// (중간에 실패하면 이전 문서가 그대로 남는다)
+func (db *VectorDB) ReplaceSource(ctx context.Context, tenantID, collection, root, path string, docs []Document) ([]int, error) {
+	if err := checkEmbeddings(docs); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        DELETE FROM documents
+        WHERE tenant_id = $1 AND collection = $2 AND metadata->>'source_root' = $3 AND metadata->>'source_path' = $4
+    `, tenantOrDefault(tenantID), collectionOrDefault(collection), root, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete source documents: %w", err)
+	}
+	ids, err := insertDocuments(ctx, tx, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteSource deletes every document of a source
+func (db *VectorDB) DeleteSource(ctx context.Context, tenantID, collection, root, path string) (int64, error) {
+	tag, err := db.pool.Exec(ctx, `
+        DELETE FROM documents
+        WHERE tenant_id = $1 AND collection = $2 AND metadata->>'source_root' = $3 AND metadata->>'source_path' = $4
+    `, tenantOrDefault(tenantID), collectionOrDefault(collection), root, path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete source documents: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// TouchSource records a new modification time of a source whose content did not change
+func (db *VectorDB) TouchSource(ctx context.Context, tenantID, collection, root, path, mtime string) error {
+	_, err := db.pool.Exec(ctx, `
+        UPDATE documents SET metadata = metadata || jsonb_build_object('source_mtime', $5::text)
+        WHERE tenant_id = $1 AND collection = $2 AND metadata->>'source_root' = $3 AND metadata->>'source_path' = $4
+    `, tenantOrDefault(tenantID), collectionOrDefault(collection), root, path, mtime)
+	if err != nil {
+		return fmt.Errorf("failed to update source: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package vector
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"example.com/hello/internal/dbtest"
+)
+
+// testDB connects to TEST_DATABASE_URL (pgvector가 설치된 Postgres), 없으면 test를 건너뛴다
+func testDB(t *testing.T) *VectorDB {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db, err := New(ctx, dbtest.DSN(t))
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+	dbtest.Migrate(t, db)
+	return db
+}
+
+func testEmbedding(seed float32) []float32 {
+	v := make([]float32, 1024)
+	for i := range v {
+		v[i] = seed + float32(i%7)/10
+	}
+	return v
+}
+
+// TestTenantIsolation stores a document as tenant A and reads it back as tenant B
+func TestTenantIsolation(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	tenantA, tenantB := "test-a-"+suffix, "test-b-"+suffix
+	const collection = "leak-test"
+	t.Cleanup(func() {
+		db.Pool().Exec(context.Background(), "DELETE FROM documents WHERE tenant_id = ANY($1)", []string{tenantA, tenantB})
+		db.Pool().Exec(context.Background(), "DELETE FROM collection_settings WHERE tenant_id = ANY($1)", []string{tenantA, tenantB})
+	})
+
+	id, err := db.InsertDocument(ctx, Document{
+		TenantID:   tenantA,
+		Collection: collection,
+		Content:    "tenant A secret",
+		Embedding:  testEmbedding(1),
+		Metadata:   map[string]any{MetaSourceRoot: "docs", MetaSourcePath: "secret.md", MetaSourceHash: "h"},
+	})
+	if err != nil {
+		t.Fatalf("InsertDocument: %v", err)
+	}
+	if err := db.SaveCollectionSettings(ctx, tenantA, CollectionSettings{Collection: collection, RetrievalMode: "hyde"}); err != nil {
+		t.Fatalf("SaveCollectionSettings: %v", err)
+	}
+
+	t.Run("search", func(t *testing.T) {
+		docs, err := db.SearchSimilar(ctx, testEmbedding(1), 10, SearchFilter{TenantID: tenantB, Collection: collection, Access: Access{All: true}})
+		if err != nil {
+			t.Fatalf("SearchSimilar: %v", err)
+		}
+		if len(docs) != 0 {
+			t.Errorf("tenant B found %d documents of tenant A", len(docs))
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		if doc, err := db.GetDocumentByID(ctx, tenantB, id); err == nil {
+			t.Errorf("tenant B got document %d of tenant %s", id, doc.TenantID)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := db.DeleteDocument(ctx, tenantB, id); err == nil {
+			t.Errorf("tenant B deleted a document of tenant A")
+		}
+		if n, err := db.DeleteSource(ctx, tenantB, collection, "docs", "secret.md"); err != nil || n != 0 {
+			t.Errorf("DeleteSource as tenant B = %d, %v, want 0 rows", n, err)
+		}
+		if _, err := db.GetDocumentByID(ctx, tenantA, id); err != nil {
+			t.Errorf("document of tenant A is gone after tenant B deleted it: %v", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		if n, err := db.GetDocumentCount(ctx, tenantB); err != nil || n != 0 {
+			t.Errorf("GetDocumentCount as tenant B = %d, %v, want 0", n, err)
+		}
+		if collections, err := db.Collections(ctx, tenantB); err != nil || len(collections) != 0 {
+			t.Errorf("Collections as tenant B = %v, %v, want none", collections, err)
+		}
+		if sources, err := db.Sources(ctx, tenantB, collection, "docs"); err != nil || len(sources) != 0 {
+			t.Errorf("Sources as tenant B = %v, %v, want none", sources, err)
+		}
+		if settings, err := db.ListCollectionSettings(ctx, tenantB); err != nil || len(settings) != 0 {
+			t.Errorf("ListCollectionSettings as tenant B = %v, %v, want none", settings, err)
+		}
+		if settings, err := db.GetCollectionSettings(ctx, tenantB, collection); err != nil || settings.RetrievalMode != "" {
+			t.Errorf("GetCollectionSettings as tenant B = %+v, %v, want defaults", settings, err)
+		}
+	})
+}
@@ -3,8 +3,11 @@ package vector
 import (
 	"context"
 	"fmt"
-	"log"
 
+	"example.com/hello/logging"
+	"example.com/hello/tenant"
+	"example.com/hello/tracing"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pgvector/pgvector-go"
 )
@@ -15,7 +18,13 @@ type VectorDB struct {
 
 // New creates a new VectorDB instance
 func New(ctx context.Context, connString string) (*VectorDB, error) {
-	pool, err := pgxpool.New(ctx, connString)
+	poolConfig, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
@@ -36,19 +45,29 @@ func (db *VectorDB) Close() {
 	db.pool.Close()
 }
 
+// Pool returns the underlying connection pool (다른 store들과 공유)
+func (db *VectorDB) Pool() *pgxpool.Pool {
+	return db.pool
+}
+
+// Stat returns connection pool statistics
+func (db *VectorDB) Stat() *pgxpool.Stat {
+	return db.pool.Stat()
+}
+
+// Ping verifies that the database is reachable
+func (db *VectorDB) Ping(ctx context.Context) error {
+	return db.pool.Ping(ctx)
+}
+
 // InsertDocument inserts a document with embedding
-func (db *VectorDB) InsertDocument(ctx context.Context, content string, embedding []float32) (int, error) {
-	if len(embedding) != 1024 {
-		return 0, fmt.Errorf("embedding must be 1024 dimensions, got %d", len(embedding))
+func (db *VectorDB) InsertDocument(ctx context.Context, doc Document) (int, error) {
+	if len(doc.Embedding) != 1024 {
+		return 0, fmt.Errorf("embedding must be 1024 dimensions, got %d", len(doc.Embedding))
 	}
 
 	var id int
-	err := db.pool.QueryRow(ctx, `
-        INSERT INTO documents (content, embedding)
-        VALUES ($1, $2)
-        RETURNING id
-    `, content, pgvector.NewVector(embedding)).Scan(&id)
-
+	err := db.pool.QueryRow(ctx, insertDocumentQuery, insertDocumentArgs(doc)...).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert document: %w", err)
 	}
@@ -56,29 +75,109 @@ func (db *VectorDB) InsertDocument(ctx context.Context, content string, embeddin
 	return id, nil
 }
 
+// InsertDocuments inserts multiple documents in a single transaction.
+// 중간에 실패하거나 ctx가 취소되면 전체 batch가 rollback 된다.
+func (db *VectorDB) InsertDocuments(ctx context.Context, docs []Document) ([]int, error) {
+	if err := checkEmbeddings(docs); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, err := insertDocuments(ctx, tx, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return ids, nil
+}
+
+func checkEmbeddings(docs []Document) error {
+	for i, doc := range docs {
+		if len(doc.Embedding) != 1024 {
+			return fmt.Errorf("embedding %d must be 1024 dimensions, got %d", i, len(doc.Embedding))
+		}
+	}
+	return nil
+}
+
+func insertDocuments(ctx context.Context, tx pgx.Tx, docs []Document) ([]int, error) {
+	ids := make([]int, len(docs))
+	for i, doc := range docs {
+		if err := tx.QueryRow(ctx, insertDocumentQuery, insertDocumentArgs(doc)...).Scan(&ids[i]); err != nil {
+			return nil, fmt.Errorf("failed to insert document %d: %w", i, err)
+		}
+	}
+	return ids, nil
+}
+
+const insertDocumentQuery = `
+    INSERT INTO documents (tenant_id, collection, content, embedding, allowed_users, allowed_groups, metadata)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING id
+`
+
+func insertDocumentArgs(doc Document) []any {
+	return []any{
+		tenantOrDefault(doc.TenantID),
+		collectionOrDefault(doc.Collection),
+		doc.Content,
+		pgvector.NewVector(doc.Embedding),
+		nonNil(doc.AllowedUsers),
+		nonNil(doc.AllowedGroups),
+		metadataArg(doc.Metadata),
+	}
+}
+
+// metadataArg stores empty metadata as NULL
+func metadataArg(metadata map[string]any) any {
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// SearchFilter restricts which documents SearchSimilar may return
+type SearchFilter struct {
+	TenantID   string
+	Collection string
+	Access     Access
+	// WithEmbeddings면 저장된 embedding도 함께 반환 (MMR 등 후처리용)
+	WithEmbeddings bool
+}
+
 // SearchSimilar searches for similar documents
-func (db *VectorDB) SearchSimilar(ctx context.Context, queryVector []float32, limit int) ([]Document, error) {
+func (db *VectorDB) SearchSimilar(ctx context.Context, queryVector []float32, limit int, filter SearchFilter) ([]Document, error) {
 	if len(queryVector) != 1024 {
 		return nil, fmt.Errorf("query vector must be 1024 dimensions, got %d", len(queryVector))
 	}
 
+	columns := "id, tenant_id, collection, content, metadata, embedding <=> $1 AS distance"
+	if filter.WithEmbeddings {
+		columns += ", embedding"
+	}
 	query := `
-        SELECT id, content, embedding <=> $1 AS distance
+        SELECT ` + columns + `
         FROM documents
+        WHERE tenant_id = $7 AND collection = $3 AND ` + aclCondition(4, 5, 6) + `
         ORDER BY embedding <=> $1
         LIMIT $2
     `
 	vec := pgvector.NewVector(queryVector)
-	// 🔍 디버깅: 벡터 첫 10개 값 출력
-	log.Printf("🔍 Query vector (first 10): %v...", queryVector[:10])
-	log.Printf("🔍 Converted pgvector: %v", vec)
-	log.Printf("🔍 Query: %s", query)
-	log.Printf("🔍 Limit: %d", limit)
-
-	// 쿼리 로그 출력
-	log.Printf("Executing query: %s\nParams: vector(len=%d), limit=%d", query, len(queryVector), limit)
+	logging.FromContext(ctx).Debug("searching similar documents", logging.Vector("query_vector", queryVector),
+		"limit", limit, "tenant", tenantOrDefault(filter.TenantID), "collection", collectionOrDefault(filter.Collection))
 
-	rows, err := db.pool.Query(ctx, query, vec, limit)
+	// ACL 조건을 쿼리 안에서 적용해 권한 없는 문서가 reranker/LLM까지 가지 않도록 한다
+	rows, err := db.pool.Query(ctx, query, vec, limit, collectionOrDefault(filter.Collection),
+		filter.Access.All, filter.Access.User, nonNil(filter.Access.Groups), tenantOrDefault(filter.TenantID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
@@ -87,26 +186,34 @@ func (db *VectorDB) SearchSimilar(ctx context.Context, queryVector []float32, li
 	var documents []Document
 	for rows.Next() {
 		var doc Document
-		err := rows.Scan(&doc.ID, &doc.Content, &doc.Distance)
-		if err != nil {
+		dest := []any{&doc.ID, &doc.TenantID, &doc.Collection, &doc.Content, &doc.Metadata, &doc.Distance}
+		var embedding pgvector.Vector
+		if filter.WithEmbeddings {
+			dest = append(dest, &embedding)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
+		if filter.WithEmbeddings {
+			doc.Embedding = embedding.Slice()
+		}
 		documents = append(documents, doc)
 	}
 
 	return documents, rows.Err()
 }
 
-// GetDocumentByID retrieves a document by ID
-func (db *VectorDB) GetDocumentByID(ctx context.Context, id int) (*Document, error) {
+// GetDocumentByID retrieves a document of tenantID by ID
+func (db *VectorDB) GetDocumentByID(ctx context.Context, tenantID string, id int) (*Document, error) {
 	var doc Document
 	var embedding pgvector.Vector
 
 	err := db.pool.QueryRow(ctx, `
-        SELECT id, content, embedding, metadata, created_at
+        SELECT id, tenant_id, collection, content, embedding, allowed_users, allowed_groups, metadata
         FROM documents
-        WHERE id = $1
-    `, id).Scan(&doc.ID, &doc.Content, &embedding)
+        WHERE id = $1 AND tenant_id = $2
+    `, id, tenantOrDefault(tenantID)).Scan(&doc.ID, &doc.TenantID, &doc.Collection, &doc.Content, &embedding,
+		&doc.AllowedUsers, &doc.AllowedGroups, &doc.Metadata)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get document: %w", err)
@@ -116,9 +223,9 @@ func (db *VectorDB) GetDocumentByID(ctx context.Context, id int) (*Document, err
 	return &doc, nil
 }
 
-// DeleteDocument deletes a document by ID
-func (db *VectorDB) DeleteDocument(ctx context.Context, id int) error {
-	result, err := db.pool.Exec(ctx, "DELETE FROM documents WHERE id = $1", id)
+// DeleteDocument deletes a document of tenantID by ID
+func (db *VectorDB) DeleteDocument(ctx context.Context, tenantID string, id int) error {
+	result, err := db.pool.Exec(ctx, "DELETE FROM documents WHERE id = $1 AND tenant_id = $2", id, tenantOrDefault(tenantID))
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
@@ -130,20 +237,92 @@ func (db *VectorDB) DeleteDocument(ctx context.Context, id int) error {
 	return nil
 }
 
-// GetDocumentCount returns the total number of documents
-func (db *VectorDB) GetDocumentCount(ctx context.Context) (int, error) {
+// GetDocumentCount returns the total number of documents of tenantID
+func (db *VectorDB) GetDocumentCount(ctx context.Context, tenantID string) (int, error) {
 	var count int
-	err := db.pool.QueryRow(ctx, "SELECT COUNT(*) FROM documents").Scan(&count)
+	err := db.pool.QueryRow(ctx, "SELECT COUNT(*) FROM documents WHERE tenant_id = $1", tenantOrDefault(tenantID)).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count documents: %w", err)
 	}
 	return count, nil
 }
 
+// CollectionCount is the number of documents in one collection of a tenant
+type CollectionCount struct {
+	TenantID   string
+	Collection string
+	Count      int
+}
+
+// CountByCollection returns the number of documents in each collection of every tenant
+func (db *VectorDB) CountByCollection(ctx context.Context) ([]CollectionCount, error) {
+	rows, err := db.pool.Query(ctx, "SELECT tenant_id, collection, COUNT(*) FROM documents GROUP BY tenant_id, collection")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []CollectionCount
+	for rows.Next() {
+		var count CollectionCount
+		if err := rows.Scan(&count.TenantID, &count.Collection, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// Collections returns the names of the collections of tenantID that contain documents
+func (db *VectorDB) Collections(ctx context.Context, tenantID string) ([]string, error) {
+	rows, err := db.pool.Query(ctx, "SELECT DISTINCT collection FROM documents WHERE tenant_id = $1 ORDER BY collection", tenantOrDefault(tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []string
+	for rows.Next() {
+		var collection string
+		if err := rows.Scan(&collection); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		collections = append(collections, collection)
+	}
+	return collections, rows.Err()
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func tenantOrDefault(tenantID string) string {
+	if tenantID == "" {
+		return tenant.DefaultID
+	}
+	return tenantID
+}
+
+func collectionOrDefault(collection string) string {
+	if collection == "" {
+		return DefaultCollection
+	}
+	return collection
+}
+
 // Document represents a document with embedding
 type Document struct {
-	ID        int       `json:"id"`
-	Content   string    `json:"content"`
-	Embedding []float32 `json:"embedding,omitempty"`
-	Distance  float64   `json:"distance,omitempty"`
+	ID            int       `json:"id"`
+	TenantID      string    `json:"tenant_id"`
+	Collection    string    `json:"collection"`
+	Content       string    `json:"content"`
+	Embedding     []float32 `json:"embedding,omitempty"`
+	Distance      float64   `json:"distance,omitempty"`
+	AllowedUsers  []string  `json:"allowed_users,omitempty"`
+	AllowedGroups []string  `json:"allowed_groups,omitempty"`
+	// Metadata는 수집 출처 등 (source_path, url 등)
+	Metadata map[string]any `json:"metadata,omitempty"`
 }